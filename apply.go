@@ -0,0 +1,82 @@
+package connect
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+)
+
+// ApplyOutcome is what ApplyConnector did to the connector
+type ApplyOutcome string
+
+const (
+	ApplyCreated   ApplyOutcome = "created"
+	ApplyUpdated   ApplyOutcome = "updated"
+	ApplyUnchanged ApplyOutcome = "unchanged"
+)
+
+// ApplyResult is the outcome of ApplyConnector
+type ApplyResult struct {
+	Outcome ApplyOutcome
+	// Changes are the config keys that were added, changed or removed
+	Changes []ConfigChange
+	// Connector is the response of the create or update, nil when the connector is unchanged
+	Connector *ConnectorResponse
+}
+
+// ApplyConnector creates the connector or updates its config, the way the PUT config endpoint does.
+// The config is compared with the one on the cluster first and the update is skipped when nothing changed,
+// as every update restarts the tasks of the connector. A connector created with an InitialState goes through
+// CreateConnector, since the PUT config endpoint does not take one
+func ApplyConnector(client Connect, req ConnectorRequest) (*ApplyResult, error) {
+	if req.Name == "" {
+		return nil, errors.New("apply connector error: connector request without a name")
+	}
+	// the connector is looked up in the list as the client retries the 404 of a missing connector
+	connectors, err := client.GetConnectors()
+	if err != nil {
+		return nil, errors.Wrap(err, "apply connector error: could not list connectors")
+	}
+	var current map[string]interface{}
+	for _, name := range connectors.Connectors {
+		if name != req.Name {
+			continue
+		}
+		response, err := client.GetConnectorConfig(req.Name)
+		if err != nil && !IsNotFound(err) {
+			return nil, errors.Wrapf(err, "apply connector error: could not get config of %v", req.Name)
+		}
+		if response != nil {
+			current = response.Config
+		}
+		break
+	}
+
+	result := &ApplyResult{Outcome: ApplyUnchanged, Changes: DiffConfig(req.Config, current)}
+	if current != nil && len(result.Changes) == 0 {
+		logger.Debugf("Connector %v is unchanged", req.Name)
+		return result, nil
+	}
+	if current == nil && req.InitialState != "" {
+		result.Outcome = ApplyCreated
+		result.Connector, err = client.CreateConnector(req)
+	} else {
+		result.Connector, err = client.UpdateConnectorConfig(req)
+		// the PUT tells whether it created the connector, which may have been deleted or created since it was listed
+		if err == nil && result.Connector.Code == http.StatusCreated {
+			result.Outcome = ApplyCreated
+		} else {
+			result.Outcome = ApplyUpdated
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "apply connector error: could not apply %v", req.Name)
+	}
+	if result.Outcome == ApplyCreated {
+		logger.Infof("Created connector %v", req.Name)
+	} else {
+		logger.Infof("Updated connector %v, %d config changes", req.Name, len(result.Changes))
+	}
+	return result, nil
+}
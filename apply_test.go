@@ -0,0 +1,57 @@
+package connect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// probingConnect counts the config reads of missing connectors, each of them waits through the 404 retries of the client
+type probingConnect struct {
+	*fakeConnect
+	missing int
+}
+
+func (p *probingConnect) GetConnectorConfig(name string) (*GetConnectorConfigResponse, error) {
+	response, err := p.fakeConnect.GetConnectorConfig(name)
+	if IsNotFound(err) {
+		p.missing++
+	}
+	return response, err
+}
+
+func TestApplyConnector(t *testing.T) {
+	fake := newFakeConnect()
+	req := ConnectorRequest{Name: "orders", Config: map[string]interface{}{"connector.class": "FileStreamSink", "tasks.max": 1}}
+
+	result, err := ApplyConnector(fake, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ApplyCreated, result.Outcome)
+	assert.Len(t, result.Changes, 2)
+	assert.Equal(t, "orders", result.Connector.Name)
+
+	result, err = ApplyConnector(fake, req)
+	assert.NoError(t, err)
+	assert.Equal(t, &ApplyResult{Outcome: ApplyUnchanged}, result)
+
+	req.Config["tasks.max"] = "2"
+	result, err = ApplyConnector(fake, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ApplyUpdated, result.Outcome)
+	assert.Equal(t, []ConfigChange{{Key: "tasks.max", Type: ConfigChanged, Old: "1", New: "2"}}, result.Changes)
+	assert.Equal(t, []string{"update orders", "update orders"}, fake.calls)
+
+	_, err = ApplyConnector(fake, ConnectorRequest{Name: "paused", InitialState: "PAUSED", Config: map[string]interface{}{}})
+	assert.NoError(t, err)
+	assert.Equal(t, "create paused initial_state=PAUSED", fake.calls[len(fake.calls)-1])
+	assert.Equal(t, "PAUSED", fake.states["paused"])
+}
+
+func TestApplyConnector_Create(t *testing.T) {
+	client := &probingConnect{fakeConnect: newFakeConnect()}
+	result, err := ApplyConnector(client, ConnectorRequest{Name: "orders", Config: map[string]interface{}{"tasks.max": "1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, ApplyCreated, result.Outcome)
+	assert.Equal(t, 0, client.missing)
+	assert.Equal(t, []string{"update orders"}, client.calls)
+}
@@ -0,0 +1,408 @@
+package connect
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+)
+
+// BackupVersion is the version of the backup archive format written by this library
+const BackupVersion = 1
+
+// BackupArchive is a snapshot of the connectors of a cluster.
+// The configs are stored as returned by connect, including their secrets, so the archive must be kept somewhere safe
+type BackupArchive struct {
+	Version    int               `json:"version"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Connectors []ConnectorBackup `json:"connectors"`
+}
+
+// ConnectorBackup is the snapshot of a single connector
+type ConnectorBackup struct {
+	Name   string                 `json:"name"`
+	Type   string                 `json:"type,omitempty"`
+	Config map[string]interface{} `json:"config"`
+	State  ConnectorState         `json:"state"`
+	// Tasks is the number of tasks the connector was running
+	Tasks int `json:"tasks"`
+	// Offsets is empty when the worker does not support reading offsets
+	Offsets []ConnectorOffset `json:"offsets,omitempty"`
+}
+
+// BackupOptions selects what goes into a backup
+type BackupOptions struct {
+	// Include and Exclude are glob patterns on the connector names, every connector is included when Include is empty
+	Include []string
+	Exclude []string
+	// SkipOffsets does not read the offsets of the connectors
+	SkipOffsets bool
+}
+
+// RestoreOptions selects what is restored from a backup
+type RestoreOptions struct {
+	// Include and Exclude are glob patterns on the connector names, every connector is included when Include is empty
+	Include []string
+	Exclude []string
+	// SkipOffsets creates the connectors without restoring their offsets
+	SkipOffsets bool
+	// DryRun reports what would be restored without changing the cluster
+	DryRun bool
+}
+
+// RestoreResult is the outcome of restoring a single connector
+type RestoreResult struct {
+	Name    string
+	State   ConnectorState
+	Offsets int
+	// Skipped is the reason the connector was not restored, empty when it was restored
+	Skipped string
+	Err     error
+}
+
+// RestoreReport is the outcome of a restore
+type RestoreReport struct {
+	DryRun  bool
+	Results []RestoreResult
+}
+
+// String renders the report one connector per line
+func (r *RestoreReport) String() string {
+	var buf bytes.Buffer
+	verb := "restored"
+	if r.DryRun {
+		verb = "would restore"
+	}
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(&buf, "failed %v: %v\n", result.Name, result.Err)
+		case result.Skipped != "":
+			fmt.Fprintf(&buf, "skipped %v: %v\n", result.Name, result.Skipped)
+		default:
+			fmt.Fprintf(&buf, "%v %v (%v, %d offsets)\n", verb, result.Name, result.State, result.Offsets)
+		}
+	}
+	return buf.String()
+}
+
+// Restored returns the names of the connectors that were, or would be on a dry run, restored
+func (r *RestoreReport) Restored() []string {
+	var names []string
+	for _, result := range r.Results {
+		if result.Skipped == "" && result.Err == nil {
+			names = append(names, result.Name)
+		}
+	}
+	return names
+}
+
+// Err returns an error summarising the connectors that could not be restored, or nil if there are none
+func (r *RestoreReport) Err() error {
+	var failed []RestoreResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.Errorf("restore error: %d of %d connectors failed, first error on %v: %v",
+		len(failed), len(r.Results), failed[0].Name, failed[0].Err)
+}
+
+// Backup takes a snapshot of the config, state, task count and offsets of the connectors of the cluster.
+// Connectors deleted while the backup runs are left out. Offsets are only read from workers that support it,
+// kafka 3.5 or later
+func Backup(client Connect, options BackupOptions) (*BackupArchive, error) {
+	connectors, err := client.GetConnectors()
+	if err != nil {
+		return nil, errors.Wrap(err, "backup error: could not list connectors")
+	}
+	names := append([]string{}, connectors.Connectors...)
+	sort.Strings(names)
+
+	archive := &BackupArchive{Version: BackupVersion, CreatedAt: time.Now().UTC()}
+	offsets := &offsetsReader{client: client, enabled: !options.SkipOffsets}
+	for _, name := range names {
+		if !matchNames(name, options.Include, options.Exclude) {
+			continue
+		}
+		backup, err := backupConnector(client, name, offsets)
+		if IsNotFound(err) {
+			logger.Warnf("Connector %v was deleted during the backup, leaving it out", name)
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "backup error")
+		}
+		archive.Connectors = append(archive.Connectors, *backup)
+	}
+	return archive, nil
+}
+
+// backupConnector reads a connector, the error is a 404 when the connector does not exist anymore
+func backupConnector(client Connect, name string, offsets *offsetsReader) (*ConnectorBackup, error) {
+	config, err := client.GetConnectorConfig(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get config of %v", name)
+	}
+	status, err := client.GetConnectorStatus(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get status of %v", name)
+	}
+	tasks, err := client.GetConnectorTasks(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get tasks of %v", name)
+	}
+	backup := &ConnectorBackup{
+		Name:   name,
+		Type:   status.Type,
+		Config: config.Config,
+		State:  stateOf(status),
+		Tasks:  len(tasks.Tasks),
+	}
+	if backup.Offsets, err = offsets.read(name); err != nil {
+		return nil, errors.Wrapf(err, "could not get offsets of %v", name)
+	}
+	return backup, nil
+}
+
+// offsetsReader reads the offsets of the connectors until it finds out the workers do not support it
+type offsetsReader struct {
+	client  Connect
+	enabled bool
+	// supported is set once the workers answered an offsets request
+	supported bool
+}
+
+func (r *offsetsReader) read(name string) ([]ConnectorOffset, error) {
+	if !r.enabled {
+		return nil, nil
+	}
+	offsets, err := r.client.GetConnectorOffsets(name)
+	if err == nil {
+		r.supported = true
+		return offsets.Offsets, nil
+	}
+	if r.supported || !offsetsNotSupported(err) {
+		return nil, err
+	}
+	if IsNotFound(err) {
+		// workers without the endpoint answer 404 too, which is told apart from a deleted connector by its status
+		if _, statusErr := r.client.GetConnectorStatus(name); statusErr != nil {
+			return nil, statusErr
+		}
+	}
+	logger.WithError(err).Warn("Offsets are not supported by the worker, backing up without offsets")
+	r.enabled = false
+	return nil, nil
+}
+
+// Restore recreates the connectors of the archive in their backed up state.
+// Connectors that already exist on the cluster are skipped. Restoring offsets creates the connector stopped,
+// alters its offsets and then moves it to its state, which requires kafka 3.6 or later.
+// Workers older than kafka 3.5 start the created connectors, which are then paused when they were paused or stopped
+func Restore(client Connect, archive *BackupArchive, options RestoreOptions) (*RestoreReport, error) {
+	connectors, err := client.GetConnectors()
+	if err != nil {
+		return nil, errors.Wrap(err, "restore error: could not list connectors")
+	}
+	existing := make(map[string]bool, len(connectors.Connectors))
+	for _, name := range connectors.Connectors {
+		existing[name] = true
+	}
+
+	report := &RestoreReport{DryRun: options.DryRun}
+	for _, backup := range archive.Connectors {
+		if !matchNames(backup.Name, options.Include, options.Exclude) {
+			continue
+		}
+		result := RestoreResult{Name: backup.Name, State: backup.State}
+		if existing[backup.Name] {
+			result.Skipped = "already exists"
+			report.Results = append(report.Results, result)
+			continue
+		}
+		offsets := backup.Offsets
+		if options.SkipOffsets {
+			offsets = nil
+		}
+		result.Offsets = len(offsets)
+		if !options.DryRun {
+			_, result.Err = restoreConnector(client, backup, offsets)
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// restoreConnector creates the connector in its state, it returns whether the connector was created
+func restoreConnector(client Connect, backup ConnectorBackup, offsets []ConnectorOffset) (bool, error) {
+	state := backup.State
+	if state == "" {
+		state = StateRunning
+	}
+	req := ConnectorRequest{Name: backup.Name, Config: backup.Config}
+	if len(offsets) > 0 {
+		// offsets can only be altered on a stopped connector, which must not start before they are
+		req.InitialState = strings.ToUpper(string(StateStopped))
+	} else if state != StateRunning {
+		req.InitialState = strings.ToUpper(string(state))
+	}
+	if _, err := client.CreateConnector(req); err != nil {
+		return false, errors.Wrap(err, "could not create connector")
+	}
+	if len(offsets) == 0 {
+		if state == StateRunning {
+			return true, nil
+		}
+		// workers older than kafka 3.5 ignore the initial state and start the connector
+		return true, errors.Wrapf(moveConnector(client, backup.Name, state), "could not move connector to %v", state)
+	}
+	if _, err := client.AlterConnectorOffsets(backup.Name, offsets); err != nil {
+		if offsetsNotSupported(err) && state != StateRunning {
+			// the worker predates the offsets endpoint and the initial state, the connector was started
+			if moveErr := moveConnector(client, backup.Name, state); moveErr != nil {
+				return true, errors.Wrapf(err, "could not alter offsets nor move connector to %v: %v", state, moveErr)
+			}
+		}
+		return true, errors.Wrap(err, "could not alter offsets")
+	}
+	var err error
+	switch state {
+	case StateRunning:
+		_, err = client.ResumeConnector(backup.Name)
+	case StatePaused:
+		_, err = client.PauseConnector(backup.Name)
+	}
+	return true, errors.Wrapf(err, "could not move connector to %v", state)
+}
+
+// moveConnector pauses or stops a connector, it is paused instead of stopped on workers older than kafka 3.5
+func moveConnector(client Connect, name string, state ConnectorState) error {
+	var err error
+	switch state {
+	case StatePaused:
+		_, err = client.PauseConnector(name)
+	case StateStopped:
+		_, err = client.StopConnector(name)
+		if offsetsNotSupported(err) {
+			// the stop endpoint was added with the offsets endpoint
+			logger.Warnf("Connector %v cannot be stopped by the worker, pausing it instead", name)
+			_, err = client.PauseConnector(name)
+		}
+	}
+	return err
+}
+
+// stateOf returns the state to restore a connector in, failed and unassigned connectors are restored running
+func stateOf(status *GetConnectorStatusResponse) ConnectorState {
+	switch status.ConnectorStatus["state"] {
+	case "PAUSED":
+		return StatePaused
+	case "STOPPED":
+		return StateStopped
+	default:
+		return StateRunning
+	}
+}
+
+// offsetsNotSupported reports whether the error means the worker does not have the offsets endpoint
+func offsetsNotSupported(err error) bool {
+	switch StatusCode(err) {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// matchNames reports whether the name matches one of the include patterns, or there are none, and none of the exclude patterns
+func matchNames(name string, include, exclude []string) bool {
+	for _, pattern := range exclude {
+		if globMatch(pattern, name) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if globMatch(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the archive as JSON
+func (a *BackupArchive) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(a)
+}
+
+// WriteFile writes the archive to a file readable only by its owner, gzipped when the path ends with .gz
+func (a *BackupArchive) WriteFile(file string) error {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var gz *gzip.Writer
+	if strings.HasSuffix(file, ".gz") {
+		gz = gzip.NewWriter(&buf)
+		w = gz
+	}
+	if err := a.Write(w); err != nil {
+		return err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	return errors.Wrapf(ioutil.WriteFile(file, buf.Bytes(), 0600), "backup error: could not write %v", file)
+}
+
+// ReadBackup reads an archive written by Write, gzipped or not
+func ReadBackup(r io.Reader) (*BackupArchive, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrap(err, "backup error: invalid gzip archive")
+		}
+		if data, err = ioutil.ReadAll(gz); err != nil {
+			return nil, errors.Wrap(err, "backup error: invalid gzip archive")
+		}
+	}
+
+	archive := new(BackupArchive)
+	if err := json.Unmarshal(data, archive); err != nil {
+		return nil, errors.Wrap(err, "backup error: invalid archive")
+	}
+	if archive.Version < 1 || archive.Version > BackupVersion {
+		return nil, errors.Errorf("backup error: unsupported archive version %d, expected %d", archive.Version, BackupVersion)
+	}
+	return archive, nil
+}
+
+// ReadBackupFile reads an archive written by WriteFile
+func ReadBackupFile(file string) (*BackupArchive, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ReadBackup(bytes.NewReader(data))
+}
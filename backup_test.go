@@ -0,0 +1,169 @@
+package connect
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newBackupCluster() *fakeConnect {
+	fake := newFakeConnect()
+	fake.add("db-source", map[string]interface{}{"connector.class": "JdbcSourceConnector", "tasks.max": 2})
+	fake.setTasks("db-source", TaskStatus{ID: 0, State: "RUNNING"}, TaskStatus{ID: 1, State: "RUNNING"})
+	fake.offsets["db-source"] = []ConnectorOffset{{
+		Partition: map[string]interface{}{"table": "users"},
+		Offset:    map[string]interface{}{"incrementing": float64(42)},
+	}}
+	fake.add("s3-sink", map[string]interface{}{"connector.class": "S3SinkConnector"})
+	fake.PauseConnector("s3-sink")
+	fake.add("tmp-sink", map[string]interface{}{"connector.class": "S3SinkConnector"})
+	fake.StopConnector("tmp-sink")
+	fake.calls = nil
+	return fake
+}
+
+func TestBackup(t *testing.T) {
+	archive, err := Backup(newBackupCluster(), BackupOptions{Exclude: []string{"tmp-*"}})
+	assert.NoError(t, err)
+	assert.Equal(t, BackupVersion, archive.Version)
+	assert.Equal(t, 2, len(archive.Connectors))
+	assert.Equal(t, ConnectorBackup{
+		Name:    "db-source",
+		Config:  map[string]interface{}{"name": "db-source", "connector.class": "JdbcSourceConnector", "tasks.max": "2"},
+		State:   StateRunning,
+		Tasks:   2,
+		Offsets: []ConnectorOffset{{Partition: map[string]interface{}{"table": "users"}, Offset: map[string]interface{}{"incrementing": float64(42)}}},
+	}, archive.Connectors[0])
+	assert.Equal(t, StatePaused, archive.Connectors[1].State)
+
+	// round trip through a gzipped file
+	file := filepath.Join(t.TempDir(), "backup.json.gz")
+	assert.NoError(t, archive.WriteFile(file))
+	read, err := ReadBackupFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, archive.Connectors, read.Connectors)
+	assert.True(t, archive.CreatedAt.Equal(read.CreatedAt))
+
+	var buf bytes.Buffer
+	assert.NoError(t, archive.Write(&buf))
+	_, err = ReadBackup(strings.NewReader(strings.Replace(buf.String(), `"version": 1`, `"version": 2`, 1)))
+	assert.EqualError(t, err, "backup error: unsupported archive version 2, expected 1")
+}
+
+func TestRestore(t *testing.T) {
+	archive, err := Backup(newBackupCluster(), BackupOptions{})
+	assert.NoError(t, err)
+
+	target := newFakeConnect()
+	target.add("s3-sink", map[string]interface{}{"connector.class": "S3SinkConnector"})
+
+	report, err := Restore(target, archive, RestoreOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.Empty(t, target.calls)
+	assert.Equal(t, "would restore db-source (running, 1 offsets)\nskipped s3-sink: already exists\nwould restore tmp-sink (stopped, 0 offsets)\n", report.String())
+
+	report, err = Restore(target, archive, RestoreOptions{Include: []string{"db-*", "tmp-*"}})
+	assert.NoError(t, err)
+	assert.NoError(t, report.Err())
+	assert.Equal(t, []string{"db-source", "tmp-sink"}, report.Restored())
+	assert.Equal(t, []string{
+		"create db-source initial_state=STOPPED",
+		"alter offsets db-source (1)",
+		"resume db-source",
+		"create tmp-sink initial_state=STOPPED",
+		"stop tmp-sink",
+	}, target.calls)
+	assert.Equal(t, "RUNNING", target.states["db-source"])
+	assert.Equal(t, "STOPPED", target.states["tmp-sink"])
+	assert.Equal(t, archive.Connectors[0].Offsets, target.offsets["db-source"])
+}
+
+// preStopWorker is a worker older than kafka 3.5, it ignores the initial state and has neither the stop nor the offsets endpoint
+type preStopWorker struct {
+	*fakeConnect
+}
+
+func (w preStopWorker) CreateConnector(req ConnectorRequest) (*ConnectorResponse, error) {
+	req.InitialState = ""
+	return w.fakeConnect.CreateConnector(req)
+}
+
+func (w preStopWorker) StopConnector(name string) (*EmptyResponse, error) {
+	return nil, &APIError{Operation: "stop connector", StatusCode: 404}
+}
+
+func (w preStopWorker) AlterConnectorOffsets(name string, offsets []ConnectorOffset) (*EmptyResponse, error) {
+	return nil, &APIError{Operation: "alter connector offsets", StatusCode: 404}
+}
+
+func TestRestore_PreStopWorker(t *testing.T) {
+	archive, err := Backup(newBackupCluster(), BackupOptions{})
+	assert.NoError(t, err)
+
+	target := newFakeConnect()
+	report, err := Restore(preStopWorker{target}, archive, RestoreOptions{SkipOffsets: true, Include: []string{"*-sink"}})
+	assert.NoError(t, err)
+	assert.NoError(t, report.Err())
+	assert.Equal(t, []string{"create s3-sink", "pause s3-sink", "create tmp-sink", "pause tmp-sink"}, target.calls)
+	assert.Equal(t, "PAUSED", target.states["s3-sink"])
+	assert.Equal(t, "PAUSED", target.states["tmp-sink"])
+}
+
+// deletingConnect deletes a connector when its status is read, and answers the offsets of the listed connectors with a status code
+type deletingConnect struct {
+	*fakeConnect
+	deleteOnStatus string
+	offsetsStatus  map[string]int
+}
+
+func (c *deletingConnect) GetConnectorStatus(name string) (*GetConnectorStatusResponse, error) {
+	if name == c.deleteOnStatus {
+		c.fakeConnect.DeleteConnector(name)
+	}
+	return c.fakeConnect.GetConnectorStatus(name)
+}
+
+func (c *deletingConnect) GetConnectorOffsets(name string) (*GetConnectorOffsetsResponse, error) {
+	if code, ok := c.offsetsStatus[name]; ok {
+		return nil, &APIError{Operation: "get connector offsets", StatusCode: code}
+	}
+	return c.fakeConnect.GetConnectorOffsets(name)
+}
+
+func TestBackup_DeletedConnectors(t *testing.T) {
+	fake := newBackupCluster()
+	fake.offsets["tmp-sink"] = []ConnectorOffset{{Partition: map[string]interface{}{"topic": "tmp"}}}
+	client := &deletingConnect{fakeConnect: fake, deleteOnStatus: "s3-sink"}
+
+	archive, err := Backup(client, BackupOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(archive.Connectors))
+	assert.Equal(t, "db-source", archive.Connectors[0].Name)
+	assert.Equal(t, "tmp-sink", archive.Connectors[1].Name)
+	assert.Len(t, archive.Connectors[1].Offsets, 1)
+
+	// once db-source confirmed the offsets endpoint, a 404 on the offsets of s3-sink means it was deleted
+	// and the offsets of the other connectors are still read
+	fake = newBackupCluster()
+	fake.offsets["tmp-sink"] = []ConnectorOffset{{Partition: map[string]interface{}{"topic": "tmp"}}}
+	client = &deletingConnect{fakeConnect: fake, offsetsStatus: map[string]int{"s3-sink": 404}}
+	archive, err = Backup(client, BackupOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(archive.Connectors))
+	assert.Len(t, archive.Connectors[1].Offsets, 1)
+}
+
+func TestBackup_OffsetsNotSupported(t *testing.T) {
+	fake := newBackupCluster()
+	client := &deletingConnect{fakeConnect: fake, offsetsStatus: map[string]int{"db-source": 404, "s3-sink": 404, "tmp-sink": 404}}
+
+	archive, err := Backup(client, BackupOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(archive.Connectors))
+	for _, connector := range archive.Connectors {
+		assert.Empty(t, connector.Offsets)
+	}
+}
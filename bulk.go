@@ -0,0 +1,274 @@
+package connect
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+)
+
+// BulkAction is the operation applied to every selected connector
+type BulkAction string
+
+const (
+	BulkPause   BulkAction = "pause"
+	BulkResume  BulkAction = "resume"
+	BulkStop    BulkAction = "stop"
+	BulkRestart BulkAction = "restart"
+	BulkDelete  BulkAction = "delete"
+)
+
+// Selector selects connectors, a connector is selected when it matches every field that is set
+type Selector struct {
+	// Names are glob patterns on the connector name, one of them must match
+	Names []string
+	// NameRegexp must match the connector name
+	NameRegexp *regexp.Regexp
+	// Config are glob patterns on config values, every key must be set to a matching value
+	Config map[string]string
+	// Class is the connector class, either fully qualified or its simple name
+	Class string
+	// States are the states of the connector instance, e.g. RUNNING or FAILED, one of them must match
+	States []string
+}
+
+// needsConfig reports whether the config of a connector is needed to match it
+func (s Selector) needsConfig() bool {
+	return len(s.Config) > 0 || s.Class != ""
+}
+
+func (s Selector) matchName(name string) bool {
+	if len(s.Names) > 0 && !matchNames(name, s.Names, nil) {
+		return false
+	}
+	return s.NameRegexp == nil || s.NameRegexp.MatchString(name)
+}
+
+func (s Selector) matchConfig(config map[string]interface{}) bool {
+	for key, pattern := range s.Config {
+		value, ok := config[key]
+		if !ok || !globMatch(pattern, NormalizeConfigValue(value)) {
+			return false
+		}
+	}
+	if s.Class != "" {
+		class := NormalizeConfigValue(config["connector.class"])
+		if class != s.Class && !strings.HasSuffix(class, "."+s.Class) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) matchState(state string) bool {
+	if len(s.States) == 0 {
+		return true
+	}
+	for _, want := range s.States {
+		if strings.EqualFold(want, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// BulkResult is the outcome of the action on a single connector
+type BulkResult struct {
+	Name string
+	Err  error
+}
+
+// BulkReport is the outcome of a bulk action, with one result per selected connector
+type BulkReport struct {
+	Action  BulkAction
+	Results []BulkResult
+}
+
+// Names returns the connectors the action succeeded on
+func (r *BulkReport) Names() []string {
+	var names []string
+	for _, result := range r.Results {
+		if result.Err == nil {
+			names = append(names, result.Name)
+		}
+	}
+	return names
+}
+
+// Failed returns the results of the connectors the action failed on
+func (r *BulkReport) Failed() []BulkResult {
+	var failed []BulkResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err returns an error summarising the connectors the action failed on, or nil if it succeeded on every one
+func (r *BulkReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.Errorf("%v error: %d of %d connectors failed, first error on %v: %v",
+		r.Action, len(failed), len(r.Results), failed[0].Name, failed[0].Err)
+}
+
+// Bulk applies an action to every connector matching a selector
+type Bulk struct {
+	client Connect
+
+	// Concurrency is the maximum number of connectors changed at the same time
+	Concurrency int
+	// RestartOptions are used by BulkRestart
+	RestartOptions RestartOptions
+}
+
+// NewBulk creates a bulk operator for the given connect client
+func NewBulk(client Connect) *Bulk {
+	return &Bulk{
+		client:      client,
+		Concurrency: 4,
+	}
+}
+
+// Select returns the sorted names of the connectors matching the selector
+func (b *Bulk) Select(selector Selector) ([]string, error) {
+	connectors, err := b.client.GetConnectors()
+	if err != nil {
+		return nil, errors.Wrap(err, "select error: could not list connectors")
+	}
+	var names []string
+	for _, name := range connectors.Connectors {
+		if !selector.matchName(name) {
+			continue
+		}
+		if selector.needsConfig() {
+			config, err := b.client.GetConnectorConfig(name)
+			if IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "select error: could not get config of %v", name)
+			}
+			if !selector.matchConfig(config.Config) {
+				continue
+			}
+		}
+		if len(selector.States) > 0 {
+			status, err := b.client.GetConnectorStatus(name)
+			if IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "select error: could not get status of %v", name)
+			}
+			if !selector.matchState(status.ConnectorStatus["state"]) {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Pause pauses the selected connectors
+func (b *Bulk) Pause(selector Selector) (*BulkReport, error) {
+	return b.Apply(BulkPause, selector)
+}
+
+// Resume resumes the selected connectors
+func (b *Bulk) Resume(selector Selector) (*BulkReport, error) {
+	return b.Apply(BulkResume, selector)
+}
+
+// Stop stops the selected connectors
+func (b *Bulk) Stop(selector Selector) (*BulkReport, error) {
+	return b.Apply(BulkStop, selector)
+}
+
+// Restart restarts the selected connectors with the RestartOptions
+func (b *Bulk) Restart(selector Selector) (*BulkReport, error) {
+	return b.Apply(BulkRestart, selector)
+}
+
+// Delete deletes the selected connectors
+func (b *Bulk) Delete(selector Selector) (*BulkReport, error) {
+	return b.Apply(BulkDelete, selector)
+}
+
+// Apply applies the action to every selected connector. A failure on one connector does not stop the others,
+// the returned error is only set when the connectors could not be selected
+func (b *Bulk) Apply(action BulkAction, selector Selector) (*BulkReport, error) {
+	names, err := b.Select(selector)
+	if err != nil {
+		return nil, err
+	}
+	return b.ApplyTo(action, names), nil
+}
+
+// ApplyTo applies the action to the named connectors
+func (b *Bulk) ApplyTo(action BulkAction, names []string) *BulkReport {
+	report := &BulkReport{Action: action, Results: make([]BulkResult, len(names))}
+	concurrency := b.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			report.Results[i] = BulkResult{Name: name, Err: b.apply(action, name)}
+		}(i, name)
+	}
+	wg.Wait()
+	return report
+}
+
+func (b *Bulk) apply(action BulkAction, name string) error {
+	var err error
+	switch action {
+	case BulkPause:
+		_, err = b.client.PauseConnector(name)
+	case BulkResume:
+		_, err = b.client.ResumeConnector(name)
+	case BulkStop:
+		_, err = b.client.StopConnector(name)
+	case BulkRestart:
+		if b.RestartOptions == (RestartOptions{}) {
+			_, err = b.client.RestartConnector(name)
+		} else {
+			_, err = b.client.RestartConnectorWithOptions(name, b.RestartOptions)
+		}
+	case BulkDelete:
+		_, err = b.client.DeleteConnector(name)
+	default:
+		err = errors.Errorf("unknown bulk action %v", action)
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("Could not %v connector %v", action, name)
+		return err
+	}
+	logger.Infof("Applied %v to connector %v", action, name)
+	return nil
+}
+
+// globMatch reports whether the value matches the pattern with the syntax of path.Match, * ? and [...],
+// except that * and ? also match the / of urls and paths in config values. Invalid patterns match nothing
+func globMatch(pattern, value string) bool {
+	// path.Match does not match / with wildcards, so it is swapped for a NUL on both sides
+	ok, err := path.Match(strings.ReplaceAll(pattern, "/", "\x00"), strings.ReplaceAll(value, "/", "\x00"))
+	return ok && err == nil
+}
@@ -0,0 +1,91 @@
+package connect
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingPause is a cluster that cannot pause one connector
+type failingPause struct {
+	*fakeConnect
+	name string
+}
+
+func (f failingPause) PauseConnector(name string) (*EmptyResponse, error) {
+	if name == f.name {
+		return nil, errors.New("rebalance in process")
+	}
+	return f.fakeConnect.PauseConnector(name)
+}
+
+func newBulkCluster() *fakeConnect {
+	fake := newFakeConnect()
+	fake.add("orders-jdbc-sink", map[string]interface{}{
+		"connector.class": "io.confluent.connect.jdbc.JdbcSinkConnector",
+		"connection.url":  "jdbc:postgresql://db1:5432/orders",
+	})
+	fake.add("users-jdbc-sink", map[string]interface{}{
+		"connector.class": "io.confluent.connect.jdbc.JdbcSinkConnector",
+		"connection.url":  "jdbc:postgresql://db2:5432/users",
+	})
+	fake.add("orders-s3-sink", map[string]interface{}{"connector.class": "io.confluent.connect.s3.S3SinkConnector"})
+	fake.add("events-source", map[string]interface{}{"connector.class": "FileStreamSource"})
+	fake.setState("events-source", "FAILED")
+	fake.calls = nil
+	return fake
+}
+
+func TestBulk_Select(t *testing.T) {
+	bulk := NewBulk(newBulkCluster())
+	for _, test := range []struct {
+		selector Selector
+		expected []string
+	}{
+		{Selector{}, []string{"events-source", "orders-jdbc-sink", "orders-s3-sink", "users-jdbc-sink"}},
+		{Selector{Names: []string{"orders-*"}}, []string{"orders-jdbc-sink", "orders-s3-sink"}},
+		{Selector{NameRegexp: regexp.MustCompile(`-(jdbc|s3)-`)}, []string{"orders-jdbc-sink", "orders-s3-sink", "users-jdbc-sink"}},
+		{Selector{Config: map[string]string{"connection.url": "*//db1:*"}}, []string{"orders-jdbc-sink"}},
+		{Selector{Class: "JdbcSinkConnector", Names: []string{"users-*"}}, []string{"users-jdbc-sink"}},
+		{Selector{States: []string{"failed"}}, []string{"events-source"}},
+		{Selector{Names: []string{"nothing-*"}}, nil},
+	} {
+		names, err := bulk.Select(test.selector)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, names, "%+v", test.selector)
+	}
+}
+
+func TestBulk_Apply(t *testing.T) {
+	fake := newBulkCluster()
+	bulk := NewBulk(failingPause{fakeConnect: fake, name: "orders-jdbc-sink"})
+
+	report, err := bulk.Pause(Selector{Names: []string{"*-sink"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"orders-s3-sink", "users-jdbc-sink"}, report.Names())
+	assert.Equal(t, 1, len(report.Failed()))
+	assert.EqualError(t, report.Err(), "pause error: 1 of 3 connectors failed, first error on orders-jdbc-sink: rebalance in process")
+	assert.Equal(t, "PAUSED", fake.states["users-jdbc-sink"])
+
+	bulk.RestartOptions = RestartOptions{IncludeTasks: true, OnlyFailed: true}
+	fake.calls = nil
+	report, err = bulk.Restart(Selector{States: []string{"FAILED"}})
+	assert.NoError(t, err)
+	assert.NoError(t, report.Err())
+	assert.Equal(t, []string{"restart events-source includeTasks=true onlyFailed=true"}, fake.calls)
+}
+
+func TestGlobMatch(t *testing.T) {
+	assert.True(t, globMatch("jdbc:*//db1:*", "jdbc:postgresql://db1:5432/orders"))
+	assert.True(t, globMatch("*", ""))
+	assert.True(t, globMatch("a*b*b", "abb"))
+	assert.False(t, globMatch("a*a", "a"))
+	assert.False(t, globMatch("orders", "orders-sink"))
+	assert.True(t, globMatch("orders-?", "orders-1"))
+	assert.True(t, globMatch("orders-[0-9]", "orders-7"))
+	assert.False(t, globMatch("orders-[^0-9]", "orders-7"))
+	assert.True(t, globMatch("s3://bucket/?", "s3://bucket/a"))
+	assert.False(t, globMatch("orders-[", "orders-["))
+}
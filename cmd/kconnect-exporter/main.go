@@ -0,0 +1,42 @@
+// kconnect-exporter serves the state of a kafka connect cluster as prometheus metrics
+//
+//	kconnect-exporter --url connect:8083 --listen :9840
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/kevinsamoei/kafka-connect-go/exporter"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	logger "github.com/sirupsen/logrus"
+)
+
+func main() {
+	url := flag.String("url", envOr("KCONNECT_URL", "localhost:8083"), "host:port of the connect rest API")
+	listen := flag.String("listen", ":9840", "address to serve the metrics on")
+	path := flag.String("path", "/metrics", "path to serve the metrics on")
+	flag.Parse()
+
+	metrics := exporter.NewClientMetrics()
+	client := connect.NewConnect(*url, metrics)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exporter.NewCollector(client), metrics)
+
+	http.Handle(*path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	logger.Infof("Serving metrics of %v on %v%v", *url, *listen, *path)
+	if err := http.ListenAndServe(*listen, nil); err != nil {
+		logger.WithError(err).Fatal("Could not serve metrics")
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
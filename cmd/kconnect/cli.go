@@ -0,0 +1,217 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultURL is used when neither a url nor a cluster profile is given
+const defaultURL = "localhost:8083"
+
+// globalValueFlags are the global flags that take a value, used to find the subcommand
+var globalValueFlags = []string{"cluster", "url", "config", "o", "output"}
+
+// config is the kconnect config file listing the known clusters
+//
+//	current-cluster: dev
+//	clusters:
+//	  dev:
+//	    url: localhost:8083
+//	  prod:
+//	    url: connect.prod.internal:8083
+type config struct {
+	CurrentCluster string             `yaml:"current-cluster"`
+	Clusters       map[string]cluster `yaml:"clusters"`
+}
+
+// cluster is a connect cluster profile
+type cluster struct {
+	URL string `yaml:"url"`
+}
+
+// cli holds the flags and the client shared by all commands
+type cli struct {
+	flags  *flag.FlagSet
+	stdout io.Writer
+
+	configPath  string
+	cluster     string
+	url         string
+	output      string
+	verbose     bool
+	showSecrets bool
+
+	client connect.Connect
+}
+
+func newCLI(name string, stdout, stderr io.Writer) *cli {
+	c := &cli{
+		flags:  flag.NewFlagSet("kconnect "+name, flag.ContinueOnError),
+		stdout: stdout,
+	}
+	c.flags.SetOutput(stderr)
+	c.flags.StringVar(&c.configPath, "config", os.Getenv("KCONNECT_CONFIG"), "path of the kconnect config file")
+	c.flags.StringVar(&c.cluster, "cluster", os.Getenv("KCONNECT_CLUSTER"), "cluster profile from the config file")
+	c.flags.StringVar(&c.url, "url", os.Getenv("KCONNECT_URL"), "host:port of the connect rest API, overrides the cluster profile")
+	c.flags.StringVar(&c.output, "output", "table", "output format: table, json or yaml")
+	c.flags.StringVar(&c.output, "o", "table", "shorthand for --output")
+	c.flags.BoolVar(&c.verbose, "v", false, "log the requests made to connect")
+	c.flags.BoolVar(&c.showSecrets, "show-secrets", false, "print sensitive config values instead of "+connect.RedactedValue)
+	return c
+}
+
+// parse parses the command line, allowing flags after positional arguments, and connects to the cluster
+func (c *cli) parse(args []string) ([]string, error) {
+	positional, err := c.parseOffline(args)
+	if err != nil {
+		return nil, err
+	}
+	url, err := c.resolveURL()
+	if err != nil {
+		return nil, err
+	}
+	c.client = connect.NewConnect(url)
+	return positional, nil
+}
+
+// parseOffline parses the command line like parse, for the commands that do not connect to a cluster
+func (c *cli) parseOffline(args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := c.flags.Parse(args); err != nil {
+			return nil, usagef("%v", err)
+		}
+		args = c.flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	switch c.output {
+	case "table", "json", "yaml":
+	default:
+		return nil, usagef("unknown output format %q", c.output)
+	}
+	if c.verbose {
+		logger.SetLevel(logger.InfoLevel)
+	}
+	return positional, nil
+}
+
+// resolveURL finds the address of the cluster from the flags and the config file
+func (c *cli) resolveURL() (string, error) {
+	if c.url != "" {
+		return c.url, nil
+	}
+
+	path := c.configPath
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return defaultURL, nil
+		}
+		path = filepath.Join(home, ".kconnect", "config.yaml")
+	}
+	cfg, err := loadConfig(path)
+	if os.IsNotExist(errors.Cause(err)) && c.configPath == "" {
+		if c.cluster != "" {
+			return "", usagef("cluster %q requested but there is no config file at %v", c.cluster, path)
+		}
+		return defaultURL, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	name := c.cluster
+	if name == "" {
+		name = cfg.CurrentCluster
+	}
+	if name == "" {
+		return defaultURL, nil
+	}
+	profile, ok := cfg.Clusters[name]
+	if !ok || profile.URL == "" {
+		return "", usagef("cluster %q is not defined in %v", name, path)
+	}
+	return profile.URL, nil
+}
+
+func loadConfig(path string) (*config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read config file")
+	}
+	cfg := new(config)
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, errors.Wrapf(err, "could not parse config file %v", path)
+	}
+	return cfg, nil
+}
+
+// readConnectorRequest reads a connector request or a connector manifest from a JSON or YAML file,
+// or from stdin when the path is -
+func readConnectorRequest(path string) (connect.ConnectorRequest, error) {
+	var req connect.ConnectorRequest
+	if path == "" {
+		return req, usagef("a file is required, use -f FILE")
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return req, errors.Wrap(err, "could not read connector file")
+	}
+
+	// YAML is a superset of JSON so both formats are handled by the YAML decoder
+	var header struct {
+		APIVersion string `yaml:"apiVersion"`
+	}
+	if err := yaml.Unmarshal(data, &header); err == nil && header.APIVersion != "" {
+		manifests, err := connect.ParseManifests(path, data)
+		if err != nil {
+			return req, err
+		}
+		if len(manifests) != 1 {
+			return req, usagef("connector file %v must hold a single manifest, found %d", path, len(manifests))
+		}
+		return manifests[0].ConnectorRequest(), nil
+	}
+	if err := yaml.Unmarshal(data, &req); err != nil {
+		return req, errors.Wrapf(err, "could not parse connector file %v", path)
+	}
+	if req.Name == "" {
+		return req, usagef("connector file %v has no name", path)
+	}
+	return req, nil
+}
+
+// takesValue reports whether the argument is a global flag followed by its value
+func takesValue(arg string) bool {
+	if strings.Contains(arg, "=") {
+		return false
+	}
+	name := strings.TrimLeft(arg, "-")
+	for _, flagName := range globalValueFlags {
+		if name == flagName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/kevinsamoei/kafka-connect-go/lint"
+)
+
+// connectorView is the output of get, create and update
+type connectorView struct {
+	Name   string                 `json:"name"`
+	Config map[string]interface{} `json:"config"`
+	Tasks  []int                  `json:"tasks"`
+}
+
+// statusView is the output of status
+type statusView struct {
+	Name     string               `json:"name"`
+	State    string               `json:"state"`
+	WorkerID string               `json:"worker_id"`
+	Trace    string               `json:"trace,omitempty"`
+	Tasks    []connect.TaskStatus `json:"tasks"`
+}
+
+// restoreView is the output of restore
+type restoreView struct {
+	Name    string `json:"name"`
+	State   string `json:"state"`
+	Offsets int    `json:"offsets"`
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// actionView is the output of the commands that change the state of a connector
+type actionView struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Task   *int   `json:"task,omitempty"`
+}
+
+func runList(c *cli, args []string) error {
+	args, err := c.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usagef("list takes no arguments")
+	}
+
+	resp, err := c.client.GetConnectors()
+	if err != nil {
+		return err
+	}
+	names := append([]string{}, resp.Connectors...)
+	sort.Strings(names)
+	return c.print(names, func(w io.Writer) {
+		row(w, "NAME")
+		for _, name := range names {
+			row(w, name)
+		}
+	})
+}
+
+func runGet(c *cli, args []string) error {
+	name, err := c.parseName(args)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.GetConnector(name)
+	if err != nil {
+		return err
+	}
+	return c.printConnector(resp)
+}
+
+func runStatus(c *cli, args []string) error {
+	name, err := c.parseName(args)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.GetConnectorStatus(name)
+	if err != nil {
+		return err
+	}
+
+	view := statusView{
+		Name:     resp.Name,
+		State:    resp.ConnectorStatus["state"],
+		WorkerID: resp.ConnectorStatus["worker_id"],
+		Trace:    resp.ConnectorStatus["trace"],
+		Tasks:    resp.TasksStatus,
+	}
+	return c.print(view, func(w io.Writer) {
+		row(w, "CONNECTOR", "STATE", "WORKER", "ERROR")
+		row(w, view.Name, view.State, view.WorkerID, firstLine(view.Trace))
+		fmt.Fprintln(w)
+		row(w, "TASK", "STATE", "WORKER", "ERROR")
+		for _, task := range view.Tasks {
+			row(w, task.ID, task.State, task.WorkerID, firstLine(task.Trace))
+		}
+	})
+}
+
+func runCreate(c *cli, args []string) error {
+	file := c.flags.String("f", "", "JSON or YAML file with the connector name and config, - for stdin")
+	if _, err := c.parseNone(args); err != nil {
+		return err
+	}
+	req, err := readConnectorRequest(*file)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.CreateConnector(c.client.CreateConnectorRequest(req))
+	if err != nil {
+		return err
+	}
+	return c.printConnector(resp)
+}
+
+func runUpdate(c *cli, args []string) error {
+	file := c.flags.String("f", "", "JSON or YAML file with the connector name and config, - for stdin")
+	if _, err := c.parseNone(args); err != nil {
+		return err
+	}
+	req, err := readConnectorRequest(*file)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.UpdateConnectorConfig(req)
+	if err != nil {
+		return err
+	}
+	return c.printConnector(resp)
+}
+
+func runPause(c *cli, args []string) error {
+	return c.forEach(args, "paused", func(name string) (*connect.EmptyResponse, error) {
+		return c.client.PauseConnector(name)
+	})
+}
+
+func runResume(c *cli, args []string) error {
+	return c.forEach(args, "resumed", func(name string) (*connect.EmptyResponse, error) {
+		return c.client.ResumeConnector(name)
+	})
+}
+
+func runStop(c *cli, args []string) error {
+	return c.forEach(args, "stopped", func(name string) (*connect.EmptyResponse, error) {
+		return c.client.StopConnector(name)
+	})
+}
+
+func runDelete(c *cli, args []string) error {
+	return c.forEach(args, "deleted", func(name string) (*connect.EmptyResponse, error) {
+		return c.client.DeleteConnector(name)
+	})
+}
+
+func runRestart(c *cli, args []string) error {
+	includeTasks := c.flags.Bool("include-tasks", false, "also restart the tasks of the connector")
+	onlyFailed := c.flags.Bool("only-failed", false, "only restart the connector and tasks that are FAILED")
+	task := c.flags.Int("task", -1, "restart only the task with this id")
+
+	names, err := c.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return usagef("restart requires at least one connector name")
+	}
+	if *task >= 0 {
+		if *includeTasks || *onlyFailed {
+			return usagef("--task cannot be combined with --include-tasks or --only-failed")
+		}
+		var views []actionView
+		for _, name := range names {
+			if _, err := c.client.RestartConnectorTask(name, *task); err != nil {
+				return err
+			}
+			id := *task
+			views = append(views, actionView{Name: name, Action: "restarted", Task: &id})
+		}
+		return c.printActions(views)
+	}
+
+	restart := c.client.RestartConnector
+	if *includeTasks || *onlyFailed {
+		options := connect.RestartOptions{IncludeTasks: *includeTasks, OnlyFailed: *onlyFailed}
+		restart = func(name string) (*connect.EmptyResponse, error) {
+			response, err := c.client.RestartConnectorWithOptions(name, options)
+			if err != nil {
+				return nil, err
+			}
+			return &response.EmptyResponse, nil
+		}
+	}
+	return c.run(names, "restarted", restart)
+}
+
+func runPlugins(c *cli, args []string) error {
+	if _, err := c.parseNone(args); err != nil {
+		return err
+	}
+	resp, err := c.client.GetConnectorPlugins()
+	if err != nil {
+		return err
+	}
+	plugins := resp.Plugins
+	if plugins == nil {
+		plugins = []connect.ConnectorPlugin{}
+	}
+	return c.print(plugins, func(w io.Writer) {
+		row(w, "CLASS", "TYPE", "VERSION")
+		for _, plugin := range plugins {
+			row(w, plugin.Class, plugin.Type, plugin.Version)
+		}
+	})
+}
+
+func runValidate(c *cli, args []string) error {
+	file := c.flags.String("f", "", "JSON or YAML file with the connector name and config, - for stdin")
+	if _, err := c.parseNone(args); err != nil {
+		return err
+	}
+	req, err := readConnectorRequest(*file)
+	if err != nil {
+		return err
+	}
+	class, _ := req.Config["connector.class"].(string)
+	if class == "" {
+		return usagef("connector file %v has no connector.class", *file)
+	}
+
+	resp, err := c.client.ValidatePluginConfig(class, req)
+	if err != nil {
+		return err
+	}
+
+	type keyError struct {
+		Key    string   `json:"key"`
+		Errors []string `json:"errors"`
+	}
+	var keyErrors []keyError
+	for _, config := range resp.ConfigInfos {
+		name, _ := config.Value["name"].(string)
+		var messages []string
+		if values, ok := config.Value["errors"].([]interface{}); ok {
+			for _, message := range values {
+				messages = append(messages, fmt.Sprint(message))
+			}
+		}
+		if len(messages) > 0 {
+			keyErrors = append(keyErrors, keyError{Key: name, Errors: messages})
+		}
+	}
+
+	view := struct {
+		Name       string     `json:"name"`
+		ErrorCount int        `json:"error_count"`
+		Errors     []keyError `json:"errors"`
+	}{Name: resp.Name, ErrorCount: resp.ErrorCount, Errors: keyErrors}
+	err = c.print(view, func(w io.Writer) {
+		if view.ErrorCount == 0 {
+			fmt.Fprintf(w, "%v is valid\n", req.Name)
+			return
+		}
+		row(w, "KEY", "ERROR")
+		for _, keyErr := range keyErrors {
+			for _, message := range keyErr.Errors {
+				row(w, keyErr.Key, message)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if resp.ErrorCount > 0 {
+		return &validationError{count: resp.ErrorCount}
+	}
+	return nil
+}
+
+// parseName parses the command line of commands that take a single connector name
+func (c *cli) parseName(args []string) (string, error) {
+	args, err := c.parse(args)
+	if err != nil {
+		return "", err
+	}
+	if len(args) != 1 {
+		return "", usagef("expected a single connector name, got %d arguments", len(args))
+	}
+	return args[0], nil
+}
+
+// parseNone parses the command line of commands that take no positional arguments
+func (c *cli) parseNone(args []string) ([]string, error) {
+	args, err := c.parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 0 {
+		return nil, usagef("unexpected arguments %v", strings.Join(args, " "))
+	}
+	return args, nil
+}
+
+func runBackup(c *cli, args []string) error {
+	file := c.flags.String("f", "", "archive file to write, gzipped when it ends with .gz")
+	include := c.flags.String("include", "", "comma separated glob patterns of the connectors to back up")
+	exclude := c.flags.String("exclude", "", "comma separated glob patterns of the connectors to leave out")
+	skipOffsets := c.flags.Bool("skip-offsets", false, "do not back up the offsets of the connectors")
+	if _, err := c.parseNone(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return usagef("backup requires -f FILE")
+	}
+	archive, err := connect.Backup(c.client, connect.BackupOptions{
+		Include:     splitList(*include),
+		Exclude:     splitList(*exclude),
+		SkipOffsets: *skipOffsets,
+	})
+	if err != nil {
+		return err
+	}
+	if err := archive.WriteFile(*file); err != nil {
+		return err
+	}
+	views := []actionView{}
+	for _, connector := range archive.Connectors {
+		views = append(views, actionView{Name: connector.Name, Action: "backed up"})
+	}
+	return c.printActions(views)
+}
+
+func runRestore(c *cli, args []string) error {
+	file := c.flags.String("f", "", "archive file written by backup")
+	include := c.flags.String("include", "", "comma separated glob patterns of the connectors to restore")
+	exclude := c.flags.String("exclude", "", "comma separated glob patterns of the connectors to leave out")
+	skipOffsets := c.flags.Bool("skip-offsets", false, "do not restore the offsets of the connectors")
+	dryRun := c.flags.Bool("dry-run", false, "show what would be restored without changing the cluster")
+	if _, err := c.parseNone(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return usagef("restore requires -f FILE")
+	}
+	archive, err := connect.ReadBackupFile(*file)
+	if err != nil {
+		return err
+	}
+	report, err := connect.Restore(c.client, archive, connect.RestoreOptions{
+		Include:     splitList(*include),
+		Exclude:     splitList(*exclude),
+		SkipOffsets: *skipOffsets,
+		DryRun:      *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+	views := []restoreView{}
+	for _, result := range report.Results {
+		view := restoreView{Name: result.Name, State: string(result.State), Offsets: result.Offsets, Skipped: result.Skipped}
+		if result.Err != nil {
+			view.Error = result.Err.Error()
+		}
+		views = append(views, view)
+	}
+	if err := c.print(views, func(w io.Writer) { io.WriteString(w, report.String()) }); err != nil {
+		return err
+	}
+	return report.Err()
+}
+
+// splitList splits a comma separated flag value
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// forEach parses the connector names and applies the action to each of them
+func (c *cli) forEach(args []string, action string, apply func(string) (*connect.EmptyResponse, error)) error {
+	names, err := c.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return usagef("at least one connector name is required")
+	}
+	return c.run(names, action, apply)
+}
+
+// run applies the action to the connectors, stopping at the first error
+func (c *cli) run(names []string, action string, apply func(string) (*connect.EmptyResponse, error)) error {
+	var views []actionView
+	for _, name := range names {
+		if _, err := apply(name); err != nil {
+			return err
+		}
+		views = append(views, actionView{Name: name, Action: action})
+	}
+	return c.printActions(views)
+}
+
+func (c *cli) printActions(views []actionView) error {
+	return c.print(views, func(w io.Writer) {
+		for _, view := range views {
+			if view.Task != nil {
+				fmt.Fprintf(w, "%v task %d %v\n", view.Name, *view.Task, view.Action)
+				continue
+			}
+			fmt.Fprintf(w, "%v %v\n", view.Name, view.Action)
+		}
+	})
+}
+
+func (c *cli) printConnector(resp *connect.ConnectorResponse) error {
+	view := connectorView{Name: resp.Name, Config: resp.Config, Tasks: []int{}}
+	if !c.showSecrets {
+		view.Config = connect.DefaultRedactor.RedactConfig(resp.Config)
+	}
+	for _, task := range resp.Tasks {
+		view.Tasks = append(view.Tasks, task.TaskID)
+	}
+	return c.print(view, func(w io.Writer) {
+		row(w, "NAME", "TASKS")
+		row(w, view.Name, len(view.Tasks))
+		fmt.Fprintln(w)
+		keys := make([]string, 0, len(view.Config))
+		for key := range view.Config {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		row(w, "KEY", "VALUE")
+		for _, key := range keys {
+			row(w, key, view.Config[key])
+		}
+	})
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func runLint(c *cli, args []string) error {
+	sarif := c.flags.Bool("sarif", false, "write the findings as a SARIF log, for code scanning tools")
+	disable := c.flags.String("disable", "", "comma separated IDs of the rules to disable")
+	files, err := c.parseOffline(args)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return usagef("lint requires at least one FILE")
+	}
+
+	linter := lint.NewLinter()
+	for _, id := range splitList(*disable) {
+		linter.SetSeverity(id, lint.SeverityOff)
+	}
+	findings := []lint.Finding{}
+	for _, file := range files {
+		req, err := readConnectorRequest(file)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, linter.LintFile(file, req)...)
+	}
+
+	if *sarif {
+		err = linter.WriteSARIF(c.stdout, findings)
+	} else {
+		err = c.print(findings, func(w io.Writer) {
+			row(w, "FILE", "CONNECTOR", "SEVERITY", "RULE", "KEY", "MESSAGE")
+			for _, finding := range findings {
+				row(w, finding.File, finding.Connector, finding.Severity, finding.Rule, finding.Key, finding.Message)
+			}
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if count := lint.Count(findings, lint.SeverityError); count > 0 {
+		return &validationError{count: count}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// kconnect is a command line client for the kafka connect rest API
+//
+//	kconnect [flags] <command> [args]
+//
+// Run kconnect help for the list of commands.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+)
+
+// exit codes returned to scripts
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+	exitConflict = 4
+	exitInvalid  = 5
+)
+
+// command is a kconnect subcommand
+type command struct {
+	usage string
+	help  string
+	run   func(c *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"list":     {usage: "list", help: "list the connectors", run: runList},
+	"get":      {usage: "get NAME", help: "show the config and tasks of a connector", run: runGet},
+	"status":   {usage: "status NAME", help: "show the state of a connector and its tasks", run: runStatus},
+	"create":   {usage: "create -f FILE", help: "create a connector from a JSON or YAML file", run: runCreate},
+	"update":   {usage: "update -f FILE", help: "create or update the config of a connector from a JSON or YAML file", run: runUpdate},
+	"pause":    {usage: "pause NAME...", help: "pause connectors", run: runPause},
+	"resume":   {usage: "resume NAME...", help: "resume connectors", run: runResume},
+	"restart":  {usage: "restart [--include-tasks] [--only-failed] [--task ID] NAME...", help: "restart connectors or a single task", run: runRestart},
+	"stop":     {usage: "stop NAME...", help: "stop connectors, shutting down their tasks", run: runStop},
+	"delete":   {usage: "delete NAME...", help: "delete connectors", run: runDelete},
+	"plugins":  {usage: "plugins", help: "list the connector plugins installed on the cluster", run: runPlugins},
+	"validate": {usage: "validate -f FILE", help: "validate a connector config against its plugin", run: runValidate},
+	"lint":     {usage: "lint [--sarif] [--disable RULES] FILE...", help: "check connector files offline against the lint rules", run: runLint},
+	"backup":   {usage: "backup -f FILE [--include GLOBS] [--exclude GLOBS] [--skip-offsets]", help: "save the config, state and offsets of the connectors to an archive", run: runBackup},
+	"restore":  {usage: "restore -f FILE [--include GLOBS] [--exclude GLOBS] [--skip-offsets] [--dry-run]", help: "recreate the connectors of an archive", run: runRestore},
+}
+
+// usageError is returned for invalid arguments
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// validationError is returned when a connector config does not pass validation
+type validationError struct {
+	count int
+}
+
+func (e *validationError) Error() string {
+	return fmt.Sprintf("validation failed with %d errors", e.count)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	// the library logs to stdout, keep it out of the command output
+	logger.SetOutput(stderr)
+	logger.SetLevel(logger.WarnLevel)
+
+	name, rest := splitCommand(args)
+	if name == "" || name == "help" {
+		printUsage(stdout)
+		return exitOK
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "kconnect: unknown command %q\n", name)
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	err := cmd.run(newCLI(name, stdout, stderr), rest)
+	if err == nil {
+		return exitOK
+	}
+
+	fmt.Fprintf(stderr, "kconnect %v: %v\n", name, err)
+	return exitCode(err)
+}
+
+// splitCommand finds the subcommand among the global flags
+func splitCommand(args []string) (string, []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) > 0 && arg[0] == '-' {
+			if takesValue(arg) {
+				i++
+			}
+			continue
+		}
+		return arg, append(append([]string{}, args[:i]...), args[i+1:]...)
+	}
+	return "", nil
+}
+
+func exitCode(err error) int {
+	switch cause := errors.Cause(err).(type) {
+	case *usageError:
+		return exitUsage
+	case *validationError, *connect.ValidationError, connect.ManifestErrors:
+		return exitInvalid
+	case *connect.APIError:
+		switch {
+		case connect.IsNotFound(cause):
+			return exitNotFound
+		case connect.IsConflict(cause):
+			return exitConflict
+		}
+	}
+	return exitError
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: kconnect [--cluster NAME] [--url HOST:PORT] [--config FILE] [-o table|json|yaml] [--show-secrets] [-v] <command> [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-62v %v\n", commands[name].usage, commands[name].help)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "exit codes: 0 ok, 1 error, 2 usage, 3 not found, 4 conflict (rebalance in process), 5 validation failed")
+}
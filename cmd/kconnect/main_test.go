@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *[]string) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if r.URL.Path != "/connectors/a/restart" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		switch {
+		case r.URL.Path == "/connectors/":
+			w.Write([]byte(`["b","a"]`))
+		case r.URL.Path == "/connectors/a/status":
+			w.Write([]byte(`{"name":"a","connector":{"state":"RUNNING","worker_id":"w1:8083"},"tasks":[{"id":0,"state":"FAILED","worker_id":"w1:8083","trace":"boom\nat line"}]}`))
+		case r.URL.Path == "/connectors/a/config":
+			w.Write([]byte(`{"name":"a","connector.class":"io.example.Source"}`))
+		case r.URL.Path == "/connectors/a/tasks":
+			w.Write([]byte(`[{"id":{"connector":"a","task":0},"config":{}}]`))
+		case r.URL.Path == "/connectors/a/offsets":
+			w.Write([]byte(`{"offsets":[{"partition":{"file":"in.txt"},"offset":{"position":10}}]}`))
+		case r.URL.Path == "/connectors/a/restart":
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/connectors/busy/pause":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_code":409,"message":"rebalance in process"}`))
+		case r.URL.Path == "/connector-plugins/":
+			w.Write([]byte(`[{"class":"io.example.Source","type":"source","version":"1.0"}]`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	return server, &requests
+}
+
+func runCLI(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestList(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+	url := strings.TrimPrefix(server.URL, "http://")
+
+	code, stdout, _ := runCLI("--url", url, "list")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "NAME\na\nb\n", stdout)
+
+	code, stdout, _ = runCLI("list", "-o", "json", "--url="+url)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "[\n  \"a\",\n  \"b\"\n]\n", stdout)
+}
+
+func TestStatus(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	code, stdout, _ := runCLI("--url", strings.TrimPrefix(server.URL, "http://"), "-o", "yaml", "status", "a")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "state: RUNNING")
+	assert.Contains(t, stdout, "state: FAILED")
+}
+
+func TestRestartWithTaskOptions(t *testing.T) {
+	server, requests := newTestServer(t)
+	defer server.Close()
+
+	code, stdout, _ := runCLI("--url", strings.TrimPrefix(server.URL, "http://"), "restart", "a", "--include-tasks", "--only-failed")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "a restarted\n", stdout)
+	assert.Equal(t, []string{"POST /connectors/a/restart?includeTasks=true&onlyFailed=true"}, *requests)
+}
+
+func TestClusterProfile(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "kconnect")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	config := "current-cluster: missing\nclusters:\n  test:\n    url: " + strings.TrimPrefix(server.URL, "http://") + "\n"
+	assert.NoError(t, ioutil.WriteFile(path, []byte(config), 0600))
+
+	code, stdout, _ := runCLI("--config", path, "--cluster", "test", "plugins")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "io.example.Source")
+
+	code, _, stderr := runCLI("--config", path, "plugins")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, `cluster "missing" is not defined`)
+}
+
+func TestExitCodes(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+	url := strings.TrimPrefix(server.URL, "http://")
+
+	code, _, _ := runCLI("--url", url, "pause", "busy")
+	assert.Equal(t, exitConflict, code)
+
+	code, _, _ = runCLI("--url", url, "get")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runCLI("--url", url, "unknown")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestBackupRestore(t *testing.T) {
+	server, requests := newTestServer(t)
+	defer server.Close()
+	url := strings.TrimPrefix(server.URL, "http://")
+	file := filepath.Join(t.TempDir(), "backup.json")
+
+	code, stdout, _ := runCLI("--url", url, "backup", "-f", file, "--include", "a")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "a backed up\n", stdout)
+	data, err := ioutil.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"position": 10`)
+
+	*requests = nil
+	code, stdout, _ = runCLI("--url", url, "restore", "-f", file, "--dry-run")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "skipped a: already exists\n", stdout)
+	assert.Equal(t, []string{"GET /connectors/"}, *requests)
+}
+
+func TestLint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kconnect-lint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	good := filepath.Join(dir, "good.json")
+	bad := filepath.Join(dir, "bad.yaml")
+	assert.NoError(t, ioutil.WriteFile(good, []byte(`{"name":"orders","config":{"connector.class":"io.example.Sink","tasks.max":"2"}}`), 0600))
+	assert.NoError(t, ioutil.WriteFile(bad, []byte("name: Orders\nconfig:\n  connection.password: secret\n"), 0600))
+
+	code, stdout, _ := runCLI("lint", good)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "FILE  CONNECTOR  SEVERITY  RULE  KEY  MESSAGE\n", stdout)
+
+	// lint does not need a cluster
+	code, _, _ = runCLI("--config", filepath.Join(dir, "missing.yaml"), "--cluster", "prod", "lint", good)
+	assert.Equal(t, exitOK, code)
+
+	code, stdout, _ = runCLI("lint", "-o", "json", "--disable", "naming-convention", good, bad)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stdout, `"rule": "missing-connector-class"`)
+	assert.Contains(t, stdout, `"rule": "plaintext-secret"`)
+	assert.NotContains(t, stdout, "naming-convention")
+
+	code, stdout, _ = runCLI("lint", "--sarif", bad)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stdout, `"version": "2.1.0"`)
+	assert.Contains(t, stdout, `"uri": "`+bad+`"`)
+}
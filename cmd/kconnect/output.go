@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// print writes the value in the selected output format. Table output is rendered by the table function
+func (c *cli) print(value interface{}, table func(w io.Writer)) error {
+	switch c.output {
+	case "json":
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "could not encode output")
+		}
+		_, err = fmt.Fprintln(c.stdout, string(data))
+		return err
+	case "yaml":
+		// go through JSON so that the YAML keys match the JSON field names
+		data, err := json.Marshal(value)
+		if err != nil {
+			return errors.Wrap(err, "could not encode output")
+		}
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return errors.Wrap(err, "could not encode output")
+		}
+		data, err = yaml.Marshal(generic)
+		if err != nil {
+			return errors.Wrap(err, "could not encode output")
+		}
+		_, err = c.stdout.Write(data)
+		return err
+	default:
+		w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+		table(w)
+		return w.Flush()
+	}
+}
+
+// row writes a tab separated table row
+func row(w io.Writer, columns ...interface{}) {
+	for i, column := range columns {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, column)
+	}
+	fmt.Fprintln(w)
+}
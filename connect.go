@@ -1,11 +1,13 @@
 package connect
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/go-resty/resty/v2"
-	"github.com/pkg/errors"
 	logger "github.com/sirupsen/logrus"
+	"net/url"
 	"os"
 	"strconv"
 
@@ -20,11 +22,15 @@ type connect struct {
 	connectHost string
 }
 
-// NewConnect creates a new instance of connect
-func NewConnect(url string) Connect {
+// NewConnect creates a new instance of connect.
+// The middlewares are called around every request, in order before it and in reverse order after it
+func NewConnect(url string, middlewares ...Middleware) Connect {
 	connect := new(connect)
 	host := fmt.Sprintf("http://%s", url)
 	transport := createTransport()
+	if len(middlewares) > 0 {
+		transport = &middlewareTransport{next: transport, middlewares: middlewares}
+	}
 	httpClient := createHttpClient(transport)
 	restClient := resty.NewWithClient(httpClient)
 
@@ -41,6 +47,11 @@ func NewConnect(url string) Connect {
 	return connect
 }
 
+// newRequest creates a request for the operation, described to the middlewares by its context
+func (c *connect) newRequest(operation, connectorName string) *resty.Request {
+	return c.client.NewRequest().SetContext(withOperation(context.Background(), operation, connectorName))
+}
+
 // set up the logger
 func init() {
 	// Log as JSON instead of the default ASCII formatter.
@@ -60,8 +71,8 @@ func init() {
 func (c *connect) GetConnectors() (*GetAllConnectorsResponse, error) {
 	// get connectors
 	response := new(GetAllConnectorsResponse)
-	resp, err := c.client.NewRequest().
-		SetResult(&response).
+	resp, err := c.newRequest("get connectors", "").
+		SetResult(&response.Connectors).
 		Get("connectors/")
 
 	if err != nil {
@@ -70,7 +81,39 @@ func (c *connect) GetConnectors() (*GetAllConnectorsResponse, error) {
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Get connectors failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("get connectors error: %v", resp.String())
+		return nil, newAPIError("get connectors", resp)
+	}
+	response.Code = resp.StatusCode()
+	return response, nil
+}
+
+// GetConnectorsExpanded gets the status and info of all active connectors in a single request.
+// Returns ErrExpandNotSupported when the worker does not support the expand parameter, added in kafka 2.3
+// curl -i -H "Accept:application/json" "http://localhost:8083/connectors?expand=status&expand=info"
+// https://docs.confluent.io/current/connect/references/restapi.html#get--connectors
+func (c *connect) GetConnectorsExpanded() (*GetExpandedConnectorsResponse, error) {
+	response := new(GetExpandedConnectorsResponse)
+	var body json.RawMessage
+	resp, err := c.newRequest("get expanded connectors", "").
+		SetResult(&body).
+		SetQueryParamsFromValues(url.Values{"expand": []string{"status", "info"}}).
+		Get("connectors")
+
+	if err != nil {
+		logger.Errorf("Could not get expanded connectors %v", err.Error())
+		return nil, err
+	}
+	if resp.StatusCode() >= 400 {
+		logger.Errorf("Get expanded connectors failed with status code: %v", resp.StatusCode())
+		return nil, newAPIError("get expanded connectors", resp)
+	}
+	// older workers ignore the expand parameter and return the list of names
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		return nil, ErrExpandNotSupported
+	}
+	if err := json.Unmarshal(body, &response.Connectors); err != nil {
+		logger.WithError(err).Error("Could not decode expanded connectors")
+		return nil, err
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -87,7 +130,7 @@ func (c *connect) CreateConnector(req ConnectorRequest) (*ConnectorResponse, err
 	}
 
 	response := new(ConnectorResponse)
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("create connector", req.Name).
 		SetBody(body).
 		SetResult(&response).
 		Post("connectors")
@@ -96,11 +139,11 @@ func (c *connect) CreateConnector(req ConnectorRequest) (*ConnectorResponse, err
 		return nil, err
 	}
 
-	logger.WithField("create connector response", resp.String()).Info("Create connector response")
+	logger.WithField("create connector response", DefaultRedactor.RedactString(resp.String())).Info("Create connector response")
 
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Create connector failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("create connector error: %v", resp.String())
+		return nil, newAPIError("create connector", resp)
 	}
 	response.Code = resp.StatusCode()
 
@@ -113,7 +156,7 @@ func (c *connect) CreateConnector(req ConnectorRequest) (*ConnectorResponse, err
 func (c *connect) GetConnector(connectorName string) (*ConnectorResponse, error) {
 	// get connector
 	response := new(ConnectorResponse)
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("get connector", connectorName).
 		SetResult(&response).
 		SetPathParams(map[string]string{"name": connectorName}).
 		Get("connectors/{name}/")
@@ -124,7 +167,7 @@ func (c *connect) GetConnector(connectorName string) (*ConnectorResponse, error)
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Get connector failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("get connector error: %v", resp.String())
+		return nil, newAPIError("get connector", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -134,8 +177,8 @@ func (c *connect) GetConnector(connectorName string) (*ConnectorResponse, error)
 // https://docs.confluent.io/current/connect/references/restapi.html#get--connectors-(string-name)-config
 func (c *connect) GetConnectorConfig(connectorName string) (*GetConnectorConfigResponse, error) {
 	response := new(GetConnectorConfigResponse)
-	resp, err := c.client.NewRequest().
-		SetResult(&response).
+	resp, err := c.newRequest("get connector config", connectorName).
+		SetResult(&response.Config).
 		SetPathParams(map[string]string{"name": connectorName}).
 		Get("connectors/{name}/config")
 
@@ -145,7 +188,7 @@ func (c *connect) GetConnectorConfig(connectorName string) (*GetConnectorConfigR
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Get connector config failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("get connector config error: %v", resp.String())
+		return nil, newAPIError("get connector config", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -156,7 +199,7 @@ func (c *connect) GetConnectorConfig(connectorName string) (*GetConnectorConfigR
 // https://docs.confluent.io/current/connect/references/restapi.html#put--connectors-(string-name)-config
 func (c *connect) UpdateConnectorConfig(req ConnectorRequest) (*ConnectorResponse, error) {
 	response := new(ConnectorResponse)
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("update connector config", req.Name).
 		SetResult(&response).
 		SetBody(req.Config).
 		SetPathParams(map[string]string{"name": req.Name}).
@@ -168,7 +211,7 @@ func (c *connect) UpdateConnectorConfig(req ConnectorRequest) (*ConnectorRespons
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Update connector config failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("update connector config error: %v", resp.String())
+		return nil, newAPIError("update connector config", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -181,7 +224,7 @@ func (c *connect) UpdateConnectorConfig(req ConnectorRequest) (*ConnectorRespons
 // https://docs.confluent.io/current/connect/references/restapi.html#get--connectors-(string-name)-status
 func (c *connect) GetConnectorStatus(connectorName string) (*GetConnectorStatusResponse, error) {
 	response := new(GetConnectorStatusResponse)
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("get connector status", connectorName).
 		SetResult(&response).
 		SetPathParams(map[string]string{"name": connectorName}).
 		Get("connectors/{name}/status")
@@ -192,7 +235,7 @@ func (c *connect) GetConnectorStatus(connectorName string) (*GetConnectorStatusR
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Get connector status failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("get connector status error: %v", resp.String())
+		return nil, newAPIError("get connector status", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -202,7 +245,7 @@ func (c *connect) GetConnectorStatus(connectorName string) (*GetConnectorStatusR
 // https://docs.confluent.io/current/connect/references/restapi.html#post--connectors-(string-name)-restart
 func (c *connect) RestartConnector(connectorName string) (*EmptyResponse, error) {
 	response := new(EmptyResponse)
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("restart connector", connectorName).
 		SetResult(&response).
 		SetPathParams(map[string]string{"name": connectorName}).
 		Post("connectors/{name}/restart")
@@ -213,7 +256,36 @@ func (c *connect) RestartConnector(connectorName string) (*EmptyResponse, error)
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Restart connector failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("restart connector error: %v", resp.String())
+		return nil, newAPIError("restart connector", resp)
+	}
+	response.Code = resp.StatusCode()
+	return response, nil
+}
+
+// RestartConnectorWithOptions restarts the connector and, depending on the options, its tasks.
+// With OnlyFailed only the instances in the FAILED state are restarted.
+// The restart is asynchronous, the 202 response is the status of the connector with the restarted instances
+// in the RESTARTING state. Workers older than kafka 3.0 ignore the options and answer 204 without a status.
+// Return 409 (Conflict) if rebalance is in process.
+// https://kafka.apache.org/documentation/#connect_rest
+func (c *connect) RestartConnectorWithOptions(connectorName string, options RestartOptions) (*GetConnectorStatusResponse, error) {
+	response := new(GetConnectorStatusResponse)
+	resp, err := c.newRequest("restart connector", connectorName).
+		SetResult(&response).
+		SetPathParams(map[string]string{"name": connectorName}).
+		SetQueryParams(map[string]string{
+			"includeTasks": strconv.FormatBool(options.IncludeTasks),
+			"onlyFailed":   strconv.FormatBool(options.OnlyFailed),
+		}).
+		Post("connectors/{name}/restart")
+
+	if err != nil {
+		logger.Errorf("Could not restart connector %v. Got error %v", connectorName, err.Error())
+		return nil, err
+	}
+	if resp.StatusCode() >= 400 {
+		logger.Errorf("Restart connector failed with status code: %v", resp.StatusCode())
+		return nil, newAPIError("restart connector", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -224,7 +296,7 @@ func (c *connect) RestartConnector(connectorName string) (*EmptyResponse, error)
 // https://docs.confluent.io/current/connect/references/restapi.html#put--connectors-(string-name)-pause
 func (c *connect) PauseConnector(connectorName string) (*EmptyResponse, error) {
 	response := new(EmptyResponse)
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("pause connector", connectorName).
 		SetResult(&response).
 		SetPathParams(map[string]string{"name": connectorName}).
 		Put("connectors/{name}/pause")
@@ -235,7 +307,7 @@ func (c *connect) PauseConnector(connectorName string) (*EmptyResponse, error) {
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Pause connector failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("pause connector error: %v", resp.String())
+		return nil, newAPIError("pause connector", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -246,7 +318,7 @@ func (c *connect) PauseConnector(connectorName string) (*EmptyResponse, error) {
 // https://docs.confluent.io/current/connect/references/restapi.html#put--connectors-(string-name)-resume
 func (c *connect) ResumeConnector(connectorName string) (*EmptyResponse, error) {
 	response := new(EmptyResponse)
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("resume connector", connectorName).
 		SetResult(&response).
 		SetPathParams(map[string]string{"name": connectorName}).
 		Put("connectors/{name}/resume")
@@ -257,7 +329,29 @@ func (c *connect) ResumeConnector(connectorName string) (*EmptyResponse, error)
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Resume connector failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("resume connector error: %v", resp.String())
+		return nil, newAPIError("resume connector", resp)
+	}
+	response.Code = resp.StatusCode()
+	return response, nil
+}
+
+// StopConnector stops the connector and shuts down its tasks, keeping its config.
+// Unlike a paused connector, a stopped connector has no tasks assigned to workers. Requires kafka 3.5 or later.
+// https://kafka.apache.org/documentation/#connect_rest
+func (c *connect) StopConnector(connectorName string) (*EmptyResponse, error) {
+	response := new(EmptyResponse)
+	resp, err := c.newRequest("stop connector", connectorName).
+		SetResult(&response).
+		SetPathParams(map[string]string{"name": connectorName}).
+		Put("connectors/{name}/stop")
+
+	if err != nil {
+		logger.Errorf("Could not stop connector %v. Got error %v", connectorName, err.Error())
+		return nil, err
+	}
+	if resp.StatusCode() >= 400 {
+		logger.Errorf("Stop connector failed with status code: %v", resp.StatusCode())
+		return nil, newAPIError("stop connector", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -268,7 +362,7 @@ func (c *connect) ResumeConnector(connectorName string) (*EmptyResponse, error)
 // https://docs.confluent.io/current/connect/references/restapi.html#delete--connectors-(string-name)-
 func (c *connect) DeleteConnector(connectorName string) (*EmptyResponse, error) {
 	response := new(EmptyResponse)
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("delete connector", connectorName).
 		SetResult(&response).
 		SetPathParams(map[string]string{"name": connectorName}).
 		Delete("connectors/{name}")
@@ -279,7 +373,7 @@ func (c *connect) DeleteConnector(connectorName string) (*EmptyResponse, error)
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Delete connector failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("delete connector error: %v", resp.String())
+		return nil, newAPIError("delete connector", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -289,10 +383,10 @@ func (c *connect) DeleteConnector(connectorName string) (*EmptyResponse, error)
 // https://docs.confluent.io/current/connect/references/restapi.html#get--connectors-(string-name)-tasks
 func (c *connect) GetConnectorTasks(connectorName string) (*GetConnectorTasksResponse, error) {
 	response := new(GetConnectorTasksResponse)
-	resp, err := c.client.NewRequest().
-		SetResult(&response).
+	resp, err := c.newRequest("get connector tasks", connectorName).
+		SetResult(&response.Tasks).
 		SetPathParams(map[string]string{"name": connectorName}).
-		Delete("connectors/{name}")
+		Get("connectors/{name}/tasks")
 
 	if err != nil {
 		logger.Errorf("Could not get connector tasks for connector %v. Got error %v", connectorName, err.Error())
@@ -300,7 +394,50 @@ func (c *connect) GetConnectorTasks(connectorName string) (*GetConnectorTasksRes
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("get connector tasks failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("get connector tasks error: %v", resp.String())
+		return nil, newAPIError("get connector tasks", resp)
+	}
+	response.Code = resp.StatusCode()
+	return response, nil
+}
+
+// GetConnectorOffsets gets the committed offsets of the connector. Requires kafka 3.5 or later.
+// https://kafka.apache.org/documentation/#connect_rest
+func (c *connect) GetConnectorOffsets(connectorName string) (*GetConnectorOffsetsResponse, error) {
+	response := new(GetConnectorOffsetsResponse)
+	resp, err := c.newRequest("get connector offsets", connectorName).
+		SetResult(&response).
+		SetPathParams(map[string]string{"name": connectorName}).
+		Get("connectors/{name}/offsets")
+
+	if err != nil {
+		logger.Errorf("Could not get connector offsets for %v. Got error %v", connectorName, err.Error())
+		return nil, err
+	}
+	if resp.StatusCode() >= 400 {
+		logger.Errorf("Get connector offsets failed with status code: %v", resp.StatusCode())
+		return nil, newAPIError("get connector offsets", resp)
+	}
+	response.Code = resp.StatusCode()
+	return response, nil
+}
+
+// AlterConnectorOffsets overwrites the offsets of the given partitions, a nil offset resets the partition.
+// The connector must be STOPPED. Requires kafka 3.6 or later.
+// https://kafka.apache.org/documentation/#connect_rest
+func (c *connect) AlterConnectorOffsets(connectorName string, offsets []ConnectorOffset) (*EmptyResponse, error) {
+	response := new(EmptyResponse)
+	resp, err := c.newRequest("alter connector offsets", connectorName).
+		SetBody(GetConnectorOffsetsResponse{Offsets: offsets}).
+		SetPathParams(map[string]string{"name": connectorName}).
+		Patch("connectors/{name}/offsets")
+
+	if err != nil {
+		logger.Errorf("Could not alter connector offsets for %v. Got error %v", connectorName, err.Error())
+		return nil, err
+	}
+	if resp.StatusCode() >= 400 {
+		logger.Errorf("Alter connector offsets failed with status code: %v", resp.StatusCode())
+		return nil, newAPIError("alter connector offsets", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -311,7 +448,7 @@ func (c *connect) GetConnectorTasks(connectorName string) (*GetConnectorTasksRes
 func (c *connect) GetConnectorTaskStatus(connectorName string, taskId int) (*TaskStatusResponse, error) {
 	response := new(TaskStatusResponse)
 
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("get connector task status", connectorName).
 		SetResult(&response).
 		SetPathParams(map[string]string{"name": connectorName, "task_id": strconv.Itoa(taskId)}).
 		Get("connectors/{name}/tasks/{task_id}/status")
@@ -321,7 +458,7 @@ func (c *connect) GetConnectorTaskStatus(connectorName string, taskId int) (*Tas
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Get connector task status failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("get connector task status error: %v", resp.String())
+		return nil, newAPIError("get connector task status", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -332,17 +469,17 @@ func (c *connect) GetConnectorTaskStatus(connectorName string, taskId int) (*Tas
 func (c *connect) RestartConnectorTask(connectorName string, taskId int) (*EmptyResponse, error) {
 	response := new(EmptyResponse)
 
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("restart connector task", connectorName).
 		SetResult(&response).
 		SetPathParams(map[string]string{"name": connectorName, "task_id": strconv.Itoa(taskId)}).
-		Get("connectors/{name}/tasks/{task_id}/restart")
+		Post("connectors/{name}/tasks/{task_id}/restart")
 	if err != nil {
 		logger.Errorf("Could not get restart task with id %v for connector %v. Got error %v", taskId, connectorName, err.Error())
 		return nil, err
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Restart connector task failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("restart connector task error: %v", resp.String())
+		return nil, newAPIError("restart connector task", resp)
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -356,8 +493,8 @@ func (c *connect) RestartConnectorTask(connectorName string, taskId int) (*Empty
 func (c *connect) GetConnectorPlugins() (*ConnectorPluginsResponse, error) {
 	response := new(ConnectorPluginsResponse)
 
-	resp, err := c.client.NewRequest().
-		SetResult(&response).
+	resp, err := c.newRequest("get connector plugins", "").
+		SetResult(&response.Plugins).
 		Get("connector-plugins/")
 	if err != nil {
 		logger.Errorf("Could not get connector plugins. Got error %v", err.Error())
@@ -365,7 +502,10 @@ func (c *connect) GetConnectorPlugins() (*ConnectorPluginsResponse, error) {
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Get connector plugins failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("get connector plugins error: %v", resp.String())
+		return nil, newAPIError("get connector plugins", resp)
+	}
+	if len(response.Plugins) > 0 {
+		response.Class = response.Plugins[0].Class
 	}
 	response.Code = resp.StatusCode()
 	return response, nil
@@ -377,7 +517,7 @@ func (c *connect) GetConnectorPlugins() (*ConnectorPluginsResponse, error) {
 func (c *connect) ValidatePluginConfig(pluginName string, request ConnectorRequest) (*ValidateConnectorPluginResponse, error) {
 	response := new(ValidateConnectorPluginResponse)
 
-	resp, err := c.client.NewRequest().
+	resp, err := c.newRequest("validate plugins", request.Name).
 		SetResult(&response).
 		SetBody(request.Config).
 		SetPathParams(map[string]string{"name": pluginName}).
@@ -388,8 +528,9 @@ func (c *connect) ValidatePluginConfig(pluginName string, request ConnectorReque
 	}
 	if resp.StatusCode() >= 400 {
 		logger.Errorf("Validate plugins failed with status code: %v", resp.StatusCode())
-		return nil, errors.Errorf("validate plugins error: %v", resp.String())
+		return nil, newAPIError("validate plugins", resp)
 	}
+	DefaultRedactor.LearnPluginConfig(response)
 	response.Code = resp.StatusCode()
 	return response, nil
 }
@@ -397,8 +538,9 @@ func (c *connect) ValidatePluginConfig(pluginName string, request ConnectorReque
 // CreateConnectorRequest returns a valid connector request
 func (c *connect) CreateConnectorRequest(req ConnectorRequest) ConnectorRequest {
 	return ConnectorRequest{
-		Name:   req.Name,
-		Config: req.Config,
+		Name:         req.Name,
+		Config:       req.Config,
+		InitialState: req.InitialState,
 	}
 }
 
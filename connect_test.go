@@ -0,0 +1,110 @@
+package connect
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestConnect returns a client for a server answering every request with the handler
+func newTestConnect(handler http.HandlerFunc) (Connect, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	return NewConnect(strings.TrimPrefix(server.URL, "http://")), server
+}
+
+func jsonHandler(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+func TestConnect_GetConnectorsDecodesList(t *testing.T) {
+	client, server := newTestConnect(jsonHandler(200, `["a","b"]`))
+	defer server.Close()
+
+	response, err := client.GetConnectors()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, response.Connectors)
+}
+
+func TestConnect_GetConnectorsExpanded(t *testing.T) {
+	var query string
+	client, server := newTestConnect(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		jsonHandler(200, `{"a":{
+			"status":{"name":"a","connector":{"state":"RUNNING","worker_id":"w1:8083"},"tasks":[{"id":0,"state":"FAILED","worker_id":"w1:8083","trace":"boom"}],"type":"sink"},
+			"info":{"name":"a","config":{"tasks.max":"1"},"tasks":[{"connector":"a","task":0}],"type":"sink"}}}`)(w, r)
+	})
+	defer server.Close()
+
+	response, err := client.GetConnectorsExpanded()
+	assert.NoError(t, err)
+	assert.Equal(t, "expand=status&expand=info", query)
+	assert.Equal(t, "RUNNING", response.Connectors["a"].Status.ConnectorStatus["state"])
+	assert.Equal(t, "boom", response.Connectors["a"].Status.TasksStatus[0].Trace)
+	assert.Equal(t, "1", response.Connectors["a"].Info.Config["tasks.max"])
+	assert.Equal(t, "sink", response.Connectors["a"].Info.Type)
+}
+
+func TestConnect_GetConnectorsExpandedNotSupported(t *testing.T) {
+	client, server := newTestConnect(jsonHandler(200, `["a"]`))
+	defer server.Close()
+
+	_, err := client.GetConnectorsExpanded()
+	assert.Equal(t, ErrExpandNotSupported, err)
+}
+
+func TestConnect_APIError(t *testing.T) {
+	client, server := newTestConnect(jsonHandler(409, `{"error_code":409,"message":"rebalance in process"}`))
+	defer server.Close()
+
+	_, err := client.PauseConnector("a")
+	assert.True(t, IsConflict(err))
+	assert.Equal(t, 409, StatusCode(err))
+	assert.Equal(t, "rebalance in process", err.(*APIError).Message)
+}
+
+func TestConnect_Middlewares(t *testing.T) {
+	server := httptest.NewServer(jsonHandler(404, `{"error_code":404,"message":"Connector db not found"}`))
+	defer server.Close()
+
+	var calls []string
+	var infos []RequestInfo
+	middleware := func(name string) Middleware {
+		return MiddlewareFuncs{
+			BeforeFunc: func(ctx context.Context, info *RequestInfo) context.Context {
+				calls = append(calls, "before "+name)
+				return ctx
+			},
+			AfterFunc: func(ctx context.Context, info *RequestInfo) {
+				calls = append(calls, "after "+name)
+				if name == "a" {
+					infos = append(infos, *info)
+				}
+			},
+		}
+	}
+	client := NewConnect(strings.TrimPrefix(server.URL, "http://"), middleware("a"), middleware("b"))
+
+	_, err := client.GetConnectorStatus("db")
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, []string{"before a", "before b", "after b", "after a"}, calls[:4])
+
+	// not found responses are retried
+	assert.True(t, len(infos) > 1)
+	for i, info := range infos {
+		assert.Equal(t, "get connector status", info.Operation)
+		assert.Equal(t, "db", info.Connector)
+		assert.Equal(t, "GET", info.Method)
+		assert.Equal(t, "/connectors/db/status", info.Path)
+		assert.Equal(t, i+1, info.Attempt)
+		assert.Equal(t, 404, info.StatusCode)
+		assert.NoError(t, info.Err)
+	}
+}
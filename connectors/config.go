@@ -0,0 +1,104 @@
+// Package connectors holds what the typed config builders of its subpackages share, and the settings
+// common to every connector such as ErrorHandling.
+// The builders emit a connect.ConnectorRequest whose config values are all strings,
+// the way the workers return them, and check the required fields before anything is sent
+package connectors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is a connector config being built, the setters skip zero values so unset fields keep the worker defaults
+type Config map[string]interface{}
+
+// Set sets a string value
+func (c Config) Set(key, value string) {
+	if value != "" {
+		c[key] = value
+	}
+}
+
+// SetInt sets an integer value
+func (c Config) SetInt(key string, value int) {
+	if value != 0 {
+		c[key] = strconv.Itoa(value)
+	}
+}
+
+// SetBool sets a boolean value, nil keeps the worker default
+func (c Config) SetBool(key string, value *bool) {
+	if value != nil {
+		c[key] = strconv.FormatBool(*value)
+	}
+}
+
+// SetList sets a comma separated list
+func (c Config) SetList(key string, values []string) {
+	if len(values) > 0 {
+		c[key] = strings.Join(values, ",")
+	}
+}
+
+// SetMillis sets a duration in milliseconds, the unit of the *.ms keys
+func (c Config) SetMillis(key string, value time.Duration) {
+	if value != 0 {
+		c[key] = strconv.FormatInt(value.Milliseconds(), 10)
+	}
+}
+
+// Merge sets the extra values as is, they override the typed fields
+func (c Config) Merge(extra map[string]interface{}) {
+	for key, value := range extra {
+		c[key] = fmt.Sprint(value)
+	}
+}
+
+// Bool returns a pointer to the value, for the optional boolean fields of the builders
+func Bool(value bool) *bool {
+	return &value
+}
+
+// ConfigError lists what is wrong with a connector config before it is sent to the worker
+type ConfigError struct {
+	// Connector is the kind of connector being built, e.g. debezium mysql
+	Connector string
+	Problems  []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%v config error: %v", e.Connector, strings.Join(e.Problems, "; "))
+}
+
+// Check collects the problems of a config
+type Check struct {
+	connector string
+	problems  []string
+}
+
+// NewCheck starts checking the config of a kind of connector
+func NewCheck(connector string) *Check {
+	return &Check{connector: connector}
+}
+
+// Required records the key as missing when it is not set
+func (c *Check) Required(key string, set bool) {
+	if !set {
+		c.problems = append(c.problems, key+" is required")
+	}
+}
+
+// Errorf records a problem
+func (c *Check) Errorf(format string, args ...interface{}) {
+	c.problems = append(c.problems, fmt.Sprintf(format, args...))
+}
+
+// Err returns a *ConfigError with the problems, or nil if there are none
+func (c *Check) Err() error {
+	if len(c.problems) == 0 {
+		return nil
+	}
+	return &ConfigError{Connector: c.connector, Problems: c.problems}
+}
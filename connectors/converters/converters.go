@@ -0,0 +1,188 @@
+// Package converters builds the key, value and header converter settings of a connector config
+//
+//	registry := &converters.Registry{URL: "https://registry:8081", User: "connect", Password: "${file:/secrets/sr.properties:password}"}
+//	err := converters.Converters{
+//		Key:   converters.String(),
+//		Value: converters.Avro(registry),
+//	}.ApplyTo(request.Config)
+package converters
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+)
+
+// converter classes
+const (
+	StringClass     = "org.apache.kafka.connect.storage.StringConverter"
+	ByteArrayClass  = "org.apache.kafka.connect.converters.ByteArrayConverter"
+	JSONClass       = "org.apache.kafka.connect.json.JsonConverter"
+	AvroClass       = "io.confluent.connect.avro.AvroConverter"
+	ProtobufClass   = "io.confluent.connect.protobuf.ProtobufConverter"
+	JSONSchemaClass = "io.confluent.connect.json.JsonSchemaConverter"
+)
+
+// SubjectNameStrategy is how the schema registry subjects are named
+type SubjectNameStrategy string
+
+const (
+	TopicNameStrategy       SubjectNameStrategy = "io.confluent.kafka.serializers.subject.TopicNameStrategy"
+	RecordNameStrategy      SubjectNameStrategy = "io.confluent.kafka.serializers.subject.RecordNameStrategy"
+	TopicRecordNameStrategy SubjectNameStrategy = "io.confluent.kafka.serializers.subject.TopicRecordNameStrategy"
+)
+
+// Registry is the schema registry of the Avro, Protobuf and JSON Schema converters
+type Registry struct {
+	URL string
+	// User and Password are sent with basic auth when User is set
+	User     string
+	Password string
+	// SubjectNameStrategy is TopicNameStrategy when empty
+	SubjectNameStrategy SubjectNameStrategy
+	// AutoRegisterSchemas and UseLatestVersion keep the converter defaults when nil
+	AutoRegisterSchemas *bool
+	UseLatestVersion    *bool
+}
+
+// Converter is the converter of the keys, the values or the headers of the records
+type Converter struct {
+	Class string
+	// Registry is required by the Avro, Protobuf and JSON Schema converters
+	Registry *Registry
+	// Settings are set with the converter prefix, e.g. schemas.enable is set as value.converter.schemas.enable
+	Settings map[string]string
+}
+
+// String converts to and from strings
+func String() *Converter {
+	return &Converter{Class: StringClass}
+}
+
+// ByteArray passes the raw bytes through
+func ByteArray() *Converter {
+	return &Converter{Class: ByteArrayClass}
+}
+
+// JSON converts to and from JSON, with schemas embeds the schema in every message
+func JSON(schemas bool) *Converter {
+	return &Converter{Class: JSONClass, Settings: map[string]string{"schemas.enable": strconv.FormatBool(schemas)}}
+}
+
+// Avro converts to and from Avro with the schemas of the registry
+func Avro(registry *Registry) *Converter {
+	return &Converter{Class: AvroClass, Registry: registry}
+}
+
+// Protobuf converts to and from Protobuf with the schemas of the registry
+func Protobuf(registry *Registry) *Converter {
+	return &Converter{Class: ProtobufClass, Registry: registry}
+}
+
+// JSONSchema converts to and from JSON validated by the schemas of the registry
+func JSONSchema(registry *Registry) *Converter {
+	return &Converter{Class: JSONSchemaClass, Registry: registry}
+}
+
+func (c *Converter) needsRegistry() bool {
+	return c.Class == AvroClass || c.Class == ProtobufClass || c.Class == JSONSchemaClass
+}
+
+// set sets the converter of the role, key, value or header
+func (c *Converter) set(config connectors.Config, role string) {
+	prefix := role + ".converter"
+	config[prefix] = c.Class
+	for key, value := range c.Settings {
+		config.Set(prefix+"."+key, value)
+	}
+	r := c.Registry
+	if r == nil {
+		return
+	}
+	config.Set(prefix+".schema.registry.url", r.URL)
+	if r.User != "" {
+		config[prefix+".basic.auth.credentials.source"] = "USER_INFO"
+		config[prefix+".basic.auth.user.info"] = r.User + ":" + r.Password
+	}
+	// the strategy of the key converter is read from key.subject.name.strategy and the value one from value.subject.name.strategy
+	if role != "header" {
+		config.Set(prefix+"."+role+".subject.name.strategy", string(r.SubjectNameStrategy))
+	}
+	config.SetBool(prefix+".auto.register.schemas", r.AutoRegisterSchemas)
+	config.SetBool(prefix+".use.latest.version", r.UseLatestVersion)
+}
+
+func (c *Converter) check(check *connectors.Check, role string) {
+	prefix := role + ".converter"
+	if c.Class == "" {
+		check.Required(prefix, false)
+		return
+	}
+	if !c.needsRegistry() {
+		if c.Registry != nil {
+			check.Errorf("%v %v does not use a schema registry", prefix, c.Class)
+		}
+		return
+	}
+	check.Required(prefix+".schema.registry.url", c.Registry != nil && c.Registry.URL != "")
+	if c.Registry != nil && c.Registry.User == "" && c.Registry.Password != "" {
+		check.Errorf("%v.basic.auth.user.info requires a user", prefix)
+	}
+}
+
+// Converters are the converters of a connector, the nil ones keep the worker converters
+type Converters struct {
+	Key    *Converter
+	Value  *Converter
+	Header *Converter
+}
+
+// Config returns the prefixed converter keys
+func (c Converters) Config() (map[string]interface{}, error) {
+	check := connectors.NewCheck("converters")
+	config := connectors.Config{}
+	for _, role := range c.roles() {
+		role.converter.check(check, role.name)
+		role.converter.set(config, role.name)
+	}
+	if err := check.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ApplyTo replaces the settings of the converters that are set in the connector config
+func (c Converters) ApplyTo(config map[string]interface{}) error {
+	converters, err := c.Config()
+	if err != nil {
+		return err
+	}
+	for _, role := range c.roles() {
+		prefix := role.name + ".converter"
+		for key := range config {
+			if key == prefix || strings.HasPrefix(key, prefix+".") {
+				delete(config, key)
+			}
+		}
+	}
+	for key, value := range converters {
+		config[key] = value
+	}
+	return nil
+}
+
+type role struct {
+	name      string
+	converter *Converter
+}
+
+func (c Converters) roles() []role {
+	var roles []role
+	for _, r := range []role{{"key", c.Key}, {"value", c.Value}, {"header", c.Header}} {
+		if r.converter != nil {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
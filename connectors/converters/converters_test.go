@@ -0,0 +1,64 @@
+package converters
+
+import (
+	"testing"
+
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConverters_ApplyTo(t *testing.T) {
+	config := map[string]interface{}{
+		"connector.class":              "FileStreamSource",
+		"key.converter":                "org.apache.kafka.connect.json.JsonConverter",
+		"key.converter.schemas.enable": "true",
+		"header.converter":             StringClass,
+	}
+	registry := &Registry{
+		URL:                 "https://registry:8081",
+		User:                "connect",
+		Password:            "secret",
+		SubjectNameStrategy: TopicRecordNameStrategy,
+		AutoRegisterSchemas: connectors.Bool(false),
+	}
+	err := Converters{Key: String(), Value: Avro(registry)}.ApplyTo(config)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"connector.class":                               "FileStreamSource",
+		"header.converter":                              StringClass,
+		"key.converter":                                 StringClass,
+		"value.converter":                               AvroClass,
+		"value.converter.schema.registry.url":           "https://registry:8081",
+		"value.converter.basic.auth.credentials.source": "USER_INFO",
+		"value.converter.basic.auth.user.info":          "connect:secret",
+		"value.converter.value.subject.name.strategy":   string(TopicRecordNameStrategy),
+		"value.converter.auto.register.schemas":         "false",
+	}, config)
+}
+
+func TestConverters_Config(t *testing.T) {
+	config, err := Converters{
+		Key:    Protobuf(&Registry{URL: "http://registry:8081", SubjectNameStrategy: RecordNameStrategy}),
+		Value:  JSON(false),
+		Header: ByteArray(),
+	}.Config()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"key.converter":                           ProtobufClass,
+		"key.converter.schema.registry.url":       "http://registry:8081",
+		"key.converter.key.subject.name.strategy": string(RecordNameStrategy),
+		"value.converter":                         JSONClass,
+		"value.converter.schemas.enable":          "false",
+		"header.converter":                        ByteArrayClass,
+	}, config)
+
+	_, err = Converters{
+		Key:    JSONSchema(nil),
+		Value:  Avro(&Registry{URL: "http://registry:8081", Password: "secret"}),
+		Header: &Converter{Class: StringClass, Registry: &Registry{}},
+	}.Config()
+	assert.IsType(t, &connectors.ConfigError{}, err)
+	assert.EqualError(t, err, "converters config error: key.converter.schema.registry.url is required; "+
+		"value.converter.basic.auth.user.info requires a user; "+
+		"header.converter org.apache.kafka.connect.storage.StringConverter does not use a schema registry")
+}
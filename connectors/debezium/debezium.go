@@ -0,0 +1,254 @@
+// Package debezium builds the configs of the Debezium MySQL, PostgreSQL and SQL Server source connectors
+//
+//	request, err := debezium.MySQL{
+//		Common: debezium.Common{
+//			Name:             "inventory",
+//			TopicPrefix:      "dbserver1",
+//			Database:         debezium.Database{Hostname: "mysql", Port: 3306, User: "debezium", Password: "${file:/secrets/mysql.properties:password}"},
+//			TableIncludeList: []string{"inventory.customers", "inventory.orders"},
+//		},
+//		ServerID:      184054,
+//		SchemaHistory: debezium.SchemaHistory{BootstrapServers: "kafka:9092", Topic: "schema-changes.inventory"},
+//	}.Request()
+//
+// The keys are those of Debezium 2.x
+package debezium
+
+import (
+	"time"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+)
+
+// connector classes
+const (
+	MySQLClass     = "io.debezium.connector.mysql.MySqlConnector"
+	PostgresClass  = "io.debezium.connector.postgresql.PostgresConnector"
+	SQLServerClass = "io.debezium.connector.sqlserver.SqlServerConnector"
+)
+
+// SnapshotMode is how the connector snapshots the tables when it starts
+type SnapshotMode string
+
+const (
+	SnapshotInitial            SnapshotMode = "initial"
+	SnapshotInitialOnly        SnapshotMode = "initial_only"
+	SnapshotAlways             SnapshotMode = "always"
+	SnapshotWhenNeeded         SnapshotMode = "when_needed"
+	SnapshotNever              SnapshotMode = "never"
+	SnapshotNoData             SnapshotMode = "no_data"
+	SnapshotSchemaOnly         SnapshotMode = "schema_only"
+	SnapshotSchemaOnlyRecovery SnapshotMode = "schema_only_recovery"
+	SnapshotRecovery           SnapshotMode = "recovery"
+	SnapshotConfigurationBased SnapshotMode = "configuration_based"
+	SnapshotCustom             SnapshotMode = "custom"
+)
+
+// Database is the connection to the captured database
+type Database struct {
+	Hostname string
+	// Port is left to the connector default when zero
+	Port     int
+	User     string
+	Password string
+}
+
+// SchemaHistory is the kafka topic where the MySQL and SQL Server connectors store the database schema history
+type SchemaHistory struct {
+	BootstrapServers string
+	Topic            string
+}
+
+// Common are the settings of every Debezium connector
+type Common struct {
+	Name string
+	// TopicPrefix is the prefix of the change event topics, it must be unique across connectors
+	TopicPrefix  string
+	Database     Database
+	SnapshotMode SnapshotMode
+	// TableIncludeList and TableExcludeList are regular expressions on the fully qualified table names,
+	// only one of them can be set
+	TableIncludeList []string
+	TableExcludeList []string
+	// HeartbeatInterval is the interval of the heartbeat messages, disabled when zero
+	HeartbeatInterval time.Duration
+	// HeartbeatTopicsPrefix is the prefix of the heartbeat topic
+	HeartbeatTopicsPrefix string
+	TasksMax              int
+	// Extra are set as is and override the typed fields
+	Extra map[string]interface{}
+}
+
+func (c Common) config(class string) connectors.Config {
+	config := connectors.Config{"connector.class": class}
+	config.Set("topic.prefix", c.TopicPrefix)
+	config.Set("database.hostname", c.Database.Hostname)
+	config.SetInt("database.port", c.Database.Port)
+	config.Set("database.user", c.Database.User)
+	config.Set("database.password", c.Database.Password)
+	config.Set("snapshot.mode", string(c.SnapshotMode))
+	config.SetList("table.include.list", c.TableIncludeList)
+	config.SetList("table.exclude.list", c.TableExcludeList)
+	config.SetMillis("heartbeat.interval.ms", c.HeartbeatInterval)
+	config.Set("topic.heartbeat.prefix", c.HeartbeatTopicsPrefix)
+	config.SetInt("tasks.max", c.TasksMax)
+	return config
+}
+
+func (c Common) check(connector string, snapshotModes ...SnapshotMode) *connectors.Check {
+	check := connectors.NewCheck(connector)
+	check.Required("name", c.Name != "")
+	check.Required("topic.prefix", c.TopicPrefix != "")
+	check.Required("database.hostname", c.Database.Hostname != "")
+	check.Required("database.user", c.Database.User != "")
+	if c.Database.Port < 0 || c.Database.Port > 65535 {
+		check.Errorf("database.port %d is not a valid port", c.Database.Port)
+	}
+	if c.SnapshotMode != "" && !containsMode(snapshotModes, c.SnapshotMode) {
+		check.Errorf("snapshot.mode %v is not supported", c.SnapshotMode)
+	}
+	if len(c.TableIncludeList) > 0 && len(c.TableExcludeList) > 0 {
+		check.Errorf("table.include.list and table.exclude.list cannot be set together")
+	}
+	if c.HeartbeatInterval < 0 {
+		check.Errorf("heartbeat.interval.ms cannot be negative")
+	}
+	if c.TasksMax < 0 {
+		check.Errorf("tasks.max cannot be negative")
+	}
+	return check
+}
+
+func (c Common) request(config connectors.Config, check *connectors.Check) (*connect.ConnectorRequest, error) {
+	if err := check.Err(); err != nil {
+		return nil, err
+	}
+	config.Merge(c.Extra)
+	return &connect.ConnectorRequest{Name: c.Name, Config: config}, nil
+}
+
+func (h SchemaHistory) set(config connectors.Config) {
+	config.Set("schema.history.internal.kafka.bootstrap.servers", h.BootstrapServers)
+	config.Set("schema.history.internal.kafka.topic", h.Topic)
+}
+
+func (h SchemaHistory) check(check *connectors.Check) {
+	check.Required("schema.history.internal.kafka.bootstrap.servers", h.BootstrapServers != "")
+	check.Required("schema.history.internal.kafka.topic", h.Topic != "")
+}
+
+// MySQL is the Debezium MySQL connector
+type MySQL struct {
+	Common
+	// ServerID identifies the connector as a replica of the database cluster, it must be unique
+	ServerID int
+	// DatabaseIncludeList and DatabaseExcludeList are regular expressions on the database names
+	DatabaseIncludeList []string
+	DatabaseExcludeList []string
+	SchemaHistory       SchemaHistory
+}
+
+// Request builds the connector request, it returns a *connectors.ConfigError when a required field is missing
+func (m MySQL) Request() (*connect.ConnectorRequest, error) {
+	check := m.check("debezium mysql", SnapshotInitial, SnapshotInitialOnly, SnapshotAlways, SnapshotWhenNeeded,
+		SnapshotNever, SnapshotNoData, SnapshotSchemaOnly, SnapshotSchemaOnlyRecovery, SnapshotRecovery,
+		SnapshotConfigurationBased, SnapshotCustom)
+	check.Required("database.server.id", m.ServerID > 0)
+	if len(m.DatabaseIncludeList) > 0 && len(m.DatabaseExcludeList) > 0 {
+		check.Errorf("database.include.list and database.exclude.list cannot be set together")
+	}
+	m.SchemaHistory.check(check)
+
+	config := m.config(MySQLClass)
+	config.SetInt("database.server.id", m.ServerID)
+	config.SetList("database.include.list", m.DatabaseIncludeList)
+	config.SetList("database.exclude.list", m.DatabaseExcludeList)
+	m.SchemaHistory.set(config)
+	return m.request(config, check)
+}
+
+// logical decoding plugins of the PostgreSQL connector
+const (
+	PluginPgOutput    = "pgoutput"
+	PluginDecoderbufs = "decoderbufs"
+)
+
+// Postgres is the Debezium PostgreSQL connector
+type Postgres struct {
+	Common
+	// DatabaseName is the database to capture, the connector captures a single database
+	DatabaseName string
+	// PluginName is the logical decoding plugin, pgoutput or decoderbufs
+	PluginName string
+	// SlotName is the replication slot, it must be unique per database
+	SlotName string
+	// PublicationName is the publication created for pgoutput
+	PublicationName string
+	// SchemaIncludeList and SchemaExcludeList are regular expressions on the schema names
+	SchemaIncludeList []string
+	SchemaExcludeList []string
+	// HeartbeatActionQuery is run on the database with every heartbeat, to advance the slot of idle databases
+	HeartbeatActionQuery string
+}
+
+// Request builds the connector request, it returns a *connectors.ConfigError when a required field is missing
+func (p Postgres) Request() (*connect.ConnectorRequest, error) {
+	check := p.check("debezium postgres", SnapshotInitial, SnapshotInitialOnly, SnapshotAlways, SnapshotWhenNeeded,
+		SnapshotNever, SnapshotNoData, SnapshotSchemaOnly, SnapshotConfigurationBased, SnapshotCustom)
+	check.Required("database.dbname", p.DatabaseName != "")
+	switch p.PluginName {
+	case "", PluginPgOutput, PluginDecoderbufs:
+	default:
+		check.Errorf("plugin.name %v is not supported", p.PluginName)
+	}
+	if len(p.SchemaIncludeList) > 0 && len(p.SchemaExcludeList) > 0 {
+		check.Errorf("schema.include.list and schema.exclude.list cannot be set together")
+	}
+	if p.HeartbeatActionQuery != "" && p.HeartbeatInterval == 0 {
+		check.Errorf("heartbeat.action.query requires heartbeat.interval.ms")
+	}
+
+	config := p.config(PostgresClass)
+	config.Set("database.dbname", p.DatabaseName)
+	config.Set("plugin.name", p.PluginName)
+	config.Set("slot.name", p.SlotName)
+	config.Set("publication.name", p.PublicationName)
+	config.SetList("schema.include.list", p.SchemaIncludeList)
+	config.SetList("schema.exclude.list", p.SchemaExcludeList)
+	config.Set("heartbeat.action.query", p.HeartbeatActionQuery)
+	return p.request(config, check)
+}
+
+// SQLServer is the Debezium SQL Server connector
+type SQLServer struct {
+	Common
+	// DatabaseNames are the databases to capture
+	DatabaseNames []string
+	// Encrypt enables TLS to the database, nil keeps the driver default
+	Encrypt       *bool
+	SchemaHistory SchemaHistory
+}
+
+// Request builds the connector request, it returns a *connectors.ConfigError when a required field is missing
+func (s SQLServer) Request() (*connect.ConnectorRequest, error) {
+	check := s.check("debezium sqlserver", SnapshotInitial, SnapshotInitialOnly, SnapshotAlways, SnapshotWhenNeeded,
+		SnapshotNoData, SnapshotSchemaOnly, SnapshotRecovery, SnapshotConfigurationBased, SnapshotCustom)
+	check.Required("database.names", len(s.DatabaseNames) > 0)
+	s.SchemaHistory.check(check)
+
+	config := s.config(SQLServerClass)
+	config.SetList("database.names", s.DatabaseNames)
+	config.SetBool("database.encrypt", s.Encrypt)
+	s.SchemaHistory.set(config)
+	return s.request(config, check)
+}
+
+func containsMode(modes []SnapshotMode, mode SnapshotMode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package debezium
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+	"github.com/stretchr/testify/assert"
+)
+
+var database = Database{Hostname: "db", Port: 3306, User: "debezium", Password: "secret"}
+
+func TestMySQL(t *testing.T) {
+	request, err := MySQL{
+		Common: Common{
+			Name:              "inventory",
+			TopicPrefix:       "dbserver1",
+			Database:          database,
+			SnapshotMode:      SnapshotWhenNeeded,
+			TableIncludeList:  []string{"inventory.customers", "inventory.orders"},
+			HeartbeatInterval: 10 * time.Second,
+			Extra:             map[string]interface{}{"include.schema.changes": false},
+		},
+		ServerID:      184054,
+		SchemaHistory: SchemaHistory{BootstrapServers: "kafka:9092", Topic: "schema-changes.inventory"},
+	}.Request()
+	assert.NoError(t, err)
+	assert.Equal(t, "inventory", request.Name)
+	assert.Equal(t, map[string]interface{}{
+		"connector.class":        MySQLClass,
+		"topic.prefix":           "dbserver1",
+		"database.hostname":      "db",
+		"database.port":          "3306",
+		"database.user":          "debezium",
+		"database.password":      "secret",
+		"database.server.id":     "184054",
+		"snapshot.mode":          "when_needed",
+		"table.include.list":     "inventory.customers,inventory.orders",
+		"heartbeat.interval.ms":  "10000",
+		"include.schema.changes": "false",
+		"schema.history.internal.kafka.bootstrap.servers": "kafka:9092",
+		"schema.history.internal.kafka.topic":             "schema-changes.inventory",
+	}, request.Config)
+}
+
+func TestPostgres(t *testing.T) {
+	request, err := Postgres{
+		Common:               Common{Name: "pg", TopicPrefix: "pg", Database: database, HeartbeatInterval: time.Minute},
+		DatabaseName:         "orders",
+		PluginName:           PluginPgOutput,
+		SchemaIncludeList:    []string{"public"},
+		HeartbeatActionQuery: "UPDATE heartbeat SET ts = now()",
+	}.Request()
+	assert.NoError(t, err)
+	assert.Equal(t, PostgresClass, request.Config["connector.class"])
+	assert.Equal(t, "orders", request.Config["database.dbname"])
+	assert.Equal(t, "pgoutput", request.Config["plugin.name"])
+	assert.Equal(t, "public", request.Config["schema.include.list"])
+	assert.Equal(t, "60000", request.Config["heartbeat.interval.ms"])
+}
+
+func TestSQLServer(t *testing.T) {
+	request, err := SQLServer{
+		Common:        Common{Name: "mssql", TopicPrefix: "mssql", Database: database},
+		DatabaseNames: []string{"sales", "hr"},
+		Encrypt:       connectors.Bool(false),
+		SchemaHistory: SchemaHistory{BootstrapServers: "kafka:9092", Topic: "schema-changes.mssql"},
+	}.Request()
+	assert.NoError(t, err)
+	assert.Equal(t, "sales,hr", request.Config["database.names"])
+	assert.Equal(t, "false", request.Config["database.encrypt"])
+}
+
+func TestValidation(t *testing.T) {
+	_, err := MySQL{Common: Common{Name: "inventory", SnapshotMode: "initial_schema"}}.Request()
+	assert.IsType(t, &connectors.ConfigError{}, err)
+	assert.EqualError(t, err, "debezium mysql config error: topic.prefix is required; database.hostname is required; "+
+		"database.user is required; snapshot.mode initial_schema is not supported; database.server.id is required; "+
+		"schema.history.internal.kafka.bootstrap.servers is required; schema.history.internal.kafka.topic is required")
+
+	_, err = Postgres{
+		Common:     Common{Name: "pg", TopicPrefix: "pg", Database: database, SnapshotMode: SnapshotRecovery},
+		PluginName: "wal2json",
+	}.Request()
+	assert.EqualError(t, err, "debezium postgres config error: snapshot.mode recovery is not supported; "+
+		"database.dbname is required; plugin.name wal2json is not supported")
+
+	_, err = SQLServer{Common: Common{Name: "mssql", TopicPrefix: "mssql", Database: database,
+		TableIncludeList: []string{"dbo.a"}, TableExcludeList: []string{"dbo.b"}}}.Request()
+	assert.EqualError(t, err, "debezium sqlserver config error: table.include.list and table.exclude.list cannot be set together; "+
+		"database.names is required; schema.history.internal.kafka.bootstrap.servers is required; schema.history.internal.kafka.topic is required")
+}
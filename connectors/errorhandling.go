@@ -0,0 +1,155 @@
+package connectors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/pkg/errors"
+)
+
+// Tolerance is whether a connector skips the records it fails to convert or transform
+type Tolerance string
+
+const (
+	// ToleranceNone fails the task on the first error
+	ToleranceNone Tolerance = "none"
+	// ToleranceAll skips the failed records, sending them to the dead letter queue of sink connectors
+	ToleranceAll Tolerance = "all"
+)
+
+// RetryForever retries failed operations without a timeout
+const RetryForever time.Duration = -1
+
+// ErrorHandling are the errors.* settings of a connector
+type ErrorHandling struct {
+	Tolerance Tolerance
+	// RetryTimeout is how long a failed operation is retried, RetryForever retries without a timeout
+	RetryTimeout time.Duration
+	// RetryDelayMax is the maximum delay between two retries
+	RetryDelayMax time.Duration
+	// Log logs the errors, LogMessages includes the failed records in the logs
+	Log         bool
+	LogMessages bool
+	// DeadLetterQueue is the topic the failed records of sink connectors are sent to, it requires ToleranceAll
+	DeadLetterQueue                  string
+	DeadLetterQueueReplicationFactor int
+	// DeadLetterQueueContextHeaders adds the error and the origin of the record as headers
+	DeadLetterQueueContextHeaders bool
+}
+
+// Config returns the errors.* keys
+func (e ErrorHandling) Config() (map[string]interface{}, error) {
+	check := NewCheck("error handling")
+	switch e.Tolerance {
+	case "", ToleranceNone, ToleranceAll:
+	default:
+		check.Errorf("errors.tolerance %v is not supported", e.Tolerance)
+	}
+	if e.DeadLetterQueue != "" && e.Tolerance != ToleranceAll {
+		check.Errorf("errors.deadletterqueue.topic.name requires errors.tolerance all")
+	}
+	if e.DeadLetterQueue == "" && (e.DeadLetterQueueReplicationFactor != 0 || e.DeadLetterQueueContextHeaders) {
+		check.Errorf("the dead letter queue settings require errors.deadletterqueue.topic.name")
+	}
+	if e.LogMessages && !e.Log {
+		check.Errorf("errors.log.include.messages requires errors.log.enable")
+	}
+	if e.RetryTimeout < RetryForever || e.RetryDelayMax < 0 {
+		check.Errorf("retry durations cannot be negative")
+	}
+	if err := check.Err(); err != nil {
+		return nil, err
+	}
+
+	config := Config{}
+	config.Set("errors.tolerance", string(e.Tolerance))
+	if e.RetryTimeout == RetryForever {
+		config["errors.retry.timeout"] = "-1"
+	} else {
+		config.SetMillis("errors.retry.timeout", e.RetryTimeout)
+	}
+	config.SetMillis("errors.retry.delay.max.ms", e.RetryDelayMax)
+	if e.Log {
+		config["errors.log.enable"] = "true"
+	}
+	if e.LogMessages {
+		config["errors.log.include.messages"] = "true"
+	}
+	config.Set("errors.deadletterqueue.topic.name", e.DeadLetterQueue)
+	config.SetInt("errors.deadletterqueue.topic.replication.factor", e.DeadLetterQueueReplicationFactor)
+	if e.DeadLetterQueueContextHeaders {
+		config["errors.deadletterqueue.context.headers.enable"] = "true"
+	}
+	return config, nil
+}
+
+// ApplyTo replaces the errors.* settings of the config of the connector request
+func (e ErrorHandling) ApplyTo(req *connect.ConnectorRequest) error {
+	settings, err := e.Config()
+	if err != nil {
+		return err
+	}
+	if req.Config == nil {
+		req.Config = make(map[string]interface{}, len(settings))
+	}
+	for key := range req.Config {
+		if strings.HasPrefix(key, "errors.") {
+			delete(req.Config, key)
+		}
+	}
+	for key, value := range settings {
+		req.Config[key] = value
+	}
+	return nil
+}
+
+// ErrorHandlingFinding is a sink connector tolerating errors without keeping track of them
+type ErrorHandlingFinding struct {
+	Connector string
+	Problem   string
+}
+
+func (f ErrorHandlingFinding) String() string {
+	return fmt.Sprintf("%v: %v", f.Connector, f.Problem)
+}
+
+// AuditErrorHandling reports the sink connectors with errors.tolerance=all that have no dead letter queue
+// or do not log their errors, as their failed records are silently dropped
+func AuditErrorHandling(client connect.Connect) ([]ErrorHandlingFinding, error) {
+	connectors, err := client.GetConnectors()
+	if err != nil {
+		return nil, errors.Wrap(err, "error handling audit error: could not list connectors")
+	}
+	names := append([]string{}, connectors.Connectors...)
+	sort.Strings(names)
+
+	var findings []ErrorHandlingFinding
+	for _, name := range names {
+		response, err := client.GetConnector(name)
+		if connect.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "error handling audit error: could not get connector %v", name)
+		}
+		// source connectors such as the mirror ones can consume topics too, only the type tells sinks apart
+		if response.Type != "sink" {
+			continue
+		}
+		config := response.Config
+		if !strings.EqualFold(connect.NormalizeConfigValue(config["errors.tolerance"]), string(ToleranceAll)) {
+			continue
+		}
+		if connect.NormalizeConfigValue(config["errors.deadletterqueue.topic.name"]) == "" {
+			findings = append(findings, ErrorHandlingFinding{Connector: name, Problem: "errors.tolerance=all without a dead letter queue"})
+		}
+		if enabled, _ := strconv.ParseBool(connect.NormalizeConfigValue(config["errors.log.enable"])); !enabled {
+			findings = append(findings, ErrorHandlingFinding{Connector: name, Problem: "errors.tolerance=all without error logging"})
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,78 @@
+package connectors
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubConnect serves connectors, their type is the prefix of their name
+type stubConnect struct {
+	connect.Connect
+	configs map[string]map[string]interface{}
+}
+
+func (s stubConnect) GetConnectors() (*connect.GetAllConnectorsResponse, error) {
+	response := &connect.GetAllConnectorsResponse{}
+	for name := range s.configs {
+		response.Connectors = append(response.Connectors, name)
+	}
+	return response, nil
+}
+
+func (s stubConnect) GetConnector(name string) (*connect.ConnectorResponse, error) {
+	return &connect.ConnectorResponse{Name: name, Config: s.configs[name], Type: strings.SplitN(name, "-", 2)[0]}, nil
+}
+
+func TestErrorHandling_ApplyTo(t *testing.T) {
+	req := &connect.ConnectorRequest{Name: "orders", Config: map[string]interface{}{"connector.class": "JdbcSinkConnector", "errors.tolerance": "none"}}
+	err := ErrorHandling{
+		Tolerance:                     ToleranceAll,
+		RetryTimeout:                  RetryForever,
+		RetryDelayMax:                 time.Minute,
+		Log:                           true,
+		DeadLetterQueue:               "dlq-orders",
+		DeadLetterQueueContextHeaders: true,
+	}.ApplyTo(req)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"connector.class":                               "JdbcSinkConnector",
+		"errors.tolerance":                              "all",
+		"errors.retry.timeout":                          "-1",
+		"errors.retry.delay.max.ms":                     "60000",
+		"errors.log.enable":                             "true",
+		"errors.deadletterqueue.topic.name":             "dlq-orders",
+		"errors.deadletterqueue.context.headers.enable": "true",
+	}, req.Config)
+
+	// a request without a config gets one
+	req = &connect.ConnectorRequest{Name: "orders"}
+	assert.NoError(t, ErrorHandling{Tolerance: ToleranceNone}.ApplyTo(req))
+	assert.Equal(t, map[string]interface{}{"errors.tolerance": "none"}, req.Config)
+
+	_, err = ErrorHandling{DeadLetterQueue: "dlq", LogMessages: true}.Config()
+	assert.IsType(t, &ConfigError{}, err)
+	assert.EqualError(t, err, "error handling config error: errors.deadletterqueue.topic.name requires errors.tolerance all; "+
+		"errors.log.include.messages requires errors.log.enable")
+}
+
+func TestAuditErrorHandling(t *testing.T) {
+	findings, err := AuditErrorHandling(stubConnect{configs: map[string]map[string]interface{}{
+		"sink-audited":  {"topics": "a", "errors.tolerance": "all", "errors.log.enable": "true", "errors.deadletterqueue.topic.name": "dlq"},
+		"sink-silent":   {"topics.regex": "b.*", "errors.tolerance": "all"},
+		"sink-unlogged": {"topics": "c", "errors.tolerance": "ALL", "errors.deadletterqueue.topic.name": "dlq"},
+		"sink-strict":   {"topics": "d"},
+		"source-jdbc":   {"errors.tolerance": "all"},
+		// the mirror source connectors have topics to replicate and are not sinks
+		"source-mirror": {"connector.class": "org.apache.kafka.connect.mirror.MirrorSourceConnector", "topics": "orders.*", "errors.tolerance": "all"},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, []ErrorHandlingFinding{
+		{Connector: "sink-silent", Problem: "errors.tolerance=all without a dead letter queue"},
+		{Connector: "sink-silent", Problem: "errors.tolerance=all without error logging"},
+		{Connector: "sink-unlogged", Problem: "errors.tolerance=all without error logging"},
+	}, findings)
+}
@@ -0,0 +1,218 @@
+// Package jdbc builds the configs of the Confluent JDBC source and sink connectors
+//
+//	request, err := jdbc.Sink{
+//		Name:       "orders-sink",
+//		Connection: jdbc.Connection{URL: "jdbc:postgresql://db:5432/orders", User: "connect", Password: "${file:/secrets/db.properties:password}"},
+//		Topics:     []string{"orders"},
+//		InsertMode: jdbc.InsertUpsert,
+//		PKMode:     jdbc.PKRecordKey,
+//		PKFields:   []string{"id"},
+//		AutoCreate: connectors.Bool(true),
+//	}.Request()
+package jdbc
+
+import (
+	"time"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+)
+
+// connector classes
+const (
+	SourceClass = "io.confluent.connect.jdbc.JdbcSourceConnector"
+	SinkClass   = "io.confluent.connect.jdbc.JdbcSinkConnector"
+)
+
+// Connection is the connection to the database
+type Connection struct {
+	URL      string
+	User     string
+	Password string
+}
+
+func (c Connection) set(config connectors.Config) {
+	config.Set("connection.url", c.URL)
+	config.Set("connection.user", c.User)
+	config.Set("connection.password", c.Password)
+}
+
+// Mode is how the source connector detects new and updated rows
+type Mode string
+
+const (
+	ModeBulk                  Mode = "bulk"
+	ModeIncrementing          Mode = "incrementing"
+	ModeTimestamp             Mode = "timestamp"
+	ModeTimestampIncrementing Mode = "timestamp+incrementing"
+)
+
+// Source is the JDBC source connector, it copies either tables or the result of a query
+type Source struct {
+	Name       string
+	Connection Connection
+	Mode       Mode
+	// IncrementingColumn is the strictly increasing column of the incrementing modes
+	IncrementingColumn string
+	// TimestampColumns are the columns of the timestamp modes, the first one that is not null is used
+	TimestampColumns []string
+	// TableWhitelist and TableBlacklist select the copied tables, they cannot be used with Query
+	TableWhitelist []string
+	TableBlacklist []string
+	// Query is copied instead of tables, the topic is then TopicPrefix itself
+	Query string
+	// TopicPrefix is prepended to the table names to get the topic names
+	TopicPrefix  string
+	PollInterval time.Duration
+	BatchMaxRows int
+	TasksMax     int
+	// Extra are set as is and override the typed fields
+	Extra map[string]interface{}
+}
+
+// Request builds the connector request, it returns a *connectors.ConfigError when the options are missing or conflicting
+func (s Source) Request() (*connect.ConnectorRequest, error) {
+	check := connectors.NewCheck("jdbc source")
+	check.Required("name", s.Name != "")
+	check.Required("connection.url", s.Connection.URL != "")
+	check.Required("topic.prefix", s.TopicPrefix != "")
+	switch s.Mode {
+	case "":
+		check.Required("mode", false)
+	case ModeBulk:
+	case ModeIncrementing:
+		check.Required("incrementing.column.name", s.IncrementingColumn != "")
+	case ModeTimestamp:
+		check.Required("timestamp.column.name", len(s.TimestampColumns) > 0)
+	case ModeTimestampIncrementing:
+		check.Required("incrementing.column.name", s.IncrementingColumn != "")
+		check.Required("timestamp.column.name", len(s.TimestampColumns) > 0)
+	default:
+		check.Errorf("mode %v is not supported", s.Mode)
+	}
+	if s.Query != "" && (len(s.TableWhitelist) > 0 || len(s.TableBlacklist) > 0) {
+		check.Errorf("query cannot be set with table.whitelist or table.blacklist")
+	}
+	if len(s.TableWhitelist) > 0 && len(s.TableBlacklist) > 0 {
+		check.Errorf("table.whitelist and table.blacklist cannot be set together")
+	}
+	if s.PollInterval < 0 {
+		check.Errorf("poll.interval.ms cannot be negative")
+	}
+	if err := check.Err(); err != nil {
+		return nil, err
+	}
+
+	config := connectors.Config{"connector.class": SourceClass}
+	s.Connection.set(config)
+	config.Set("mode", string(s.Mode))
+	config.Set("incrementing.column.name", s.IncrementingColumn)
+	config.SetList("timestamp.column.name", s.TimestampColumns)
+	config.SetList("table.whitelist", s.TableWhitelist)
+	config.SetList("table.blacklist", s.TableBlacklist)
+	config.Set("query", s.Query)
+	config.Set("topic.prefix", s.TopicPrefix)
+	config.SetMillis("poll.interval.ms", s.PollInterval)
+	config.SetInt("batch.max.rows", s.BatchMaxRows)
+	config.SetInt("tasks.max", s.TasksMax)
+	config.Merge(s.Extra)
+	return &connect.ConnectorRequest{Name: s.Name, Config: config}, nil
+}
+
+// InsertMode is the statement the sink connector writes the records with
+type InsertMode string
+
+const (
+	InsertInsert InsertMode = "insert"
+	InsertUpsert InsertMode = "upsert"
+	InsertUpdate InsertMode = "update"
+)
+
+// PKMode is where the sink connector takes the primary key from
+type PKMode string
+
+const (
+	PKNone        PKMode = "none"
+	PKKafka       PKMode = "kafka"
+	PKRecordKey   PKMode = "record_key"
+	PKRecordValue PKMode = "record_value"
+)
+
+// Sink is the JDBC sink connector
+type Sink struct {
+	Name       string
+	Connection Connection
+	// Topics or TopicsRegex are the consumed topics, exactly one of them is required
+	Topics      []string
+	TopicsRegex string
+	InsertMode  InsertMode
+	PKMode      PKMode
+	// PKFields are the primary key columns, with PKKafka they name the topic, partition and offset columns
+	PKFields []string
+	// AutoCreate creates the missing tables and AutoEvolve adds the missing columns
+	AutoCreate *bool
+	AutoEvolve *bool
+	// DeleteEnabled deletes the row of a tombstone record, it requires PKRecordKey
+	DeleteEnabled *bool
+	// TableNameFormat is the table of a topic, ${topic} is replaced by the topic name
+	TableNameFormat string
+	BatchSize       int
+	TasksMax        int
+	// Extra are set as is and override the typed fields
+	Extra map[string]interface{}
+}
+
+// Request builds the connector request, it returns a *connectors.ConfigError when the options are missing or conflicting
+func (s Sink) Request() (*connect.ConnectorRequest, error) {
+	check := connectors.NewCheck("jdbc sink")
+	check.Required("name", s.Name != "")
+	check.Required("connection.url", s.Connection.URL != "")
+	if len(s.Topics) > 0 && s.TopicsRegex != "" {
+		check.Errorf("topics and topics.regex cannot be set together")
+	}
+	check.Required("topics", len(s.Topics) > 0 || s.TopicsRegex != "")
+	switch s.InsertMode {
+	case "", InsertInsert:
+	case InsertUpsert, InsertUpdate:
+		if s.PKMode == "" || s.PKMode == PKNone {
+			check.Errorf("insert.mode %v requires a pk.mode other than none", s.InsertMode)
+		}
+	default:
+		check.Errorf("insert.mode %v is not supported", s.InsertMode)
+	}
+	switch s.PKMode {
+	case "", PKNone:
+		if len(s.PKFields) > 0 {
+			check.Errorf("pk.fields requires a pk.mode other than none")
+		}
+	case PKKafka:
+		if len(s.PKFields) != 0 && len(s.PKFields) != 3 {
+			check.Errorf("pk.fields must name the topic, partition and offset columns with pk.mode kafka")
+		}
+	case PKRecordKey, PKRecordValue:
+	default:
+		check.Errorf("pk.mode %v is not supported", s.PKMode)
+	}
+	if s.DeleteEnabled != nil && *s.DeleteEnabled && s.PKMode != PKRecordKey {
+		check.Errorf("delete.enabled requires pk.mode record_key")
+	}
+	if err := check.Err(); err != nil {
+		return nil, err
+	}
+
+	config := connectors.Config{"connector.class": SinkClass}
+	s.Connection.set(config)
+	config.SetList("topics", s.Topics)
+	config.Set("topics.regex", s.TopicsRegex)
+	config.Set("insert.mode", string(s.InsertMode))
+	config.Set("pk.mode", string(s.PKMode))
+	config.SetList("pk.fields", s.PKFields)
+	config.SetBool("auto.create", s.AutoCreate)
+	config.SetBool("auto.evolve", s.AutoEvolve)
+	config.SetBool("delete.enabled", s.DeleteEnabled)
+	config.Set("table.name.format", s.TableNameFormat)
+	config.SetInt("batch.size", s.BatchSize)
+	config.SetInt("tasks.max", s.TasksMax)
+	config.Merge(s.Extra)
+	return &connect.ConnectorRequest{Name: s.Name, Config: config}, nil
+}
@@ -0,0 +1,95 @@
+package jdbc
+
+import (
+	"testing"
+	"time"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+	"github.com/stretchr/testify/assert"
+)
+
+var connection = Connection{URL: "jdbc:postgresql://db:5432/orders", User: "connect", Password: "secret"}
+
+func TestSource(t *testing.T) {
+	request, err := Source{
+		Name:               "orders-source",
+		Connection:         connection,
+		Mode:               ModeTimestampIncrementing,
+		IncrementingColumn: "id",
+		TimestampColumns:   []string{"updated_at", "created_at"},
+		TableWhitelist:     []string{"orders", "order_lines"},
+		TopicPrefix:        "db-",
+		PollInterval:       30 * time.Second,
+	}.Request()
+	assert.NoError(t, err)
+	assert.Equal(t, "orders-source", request.Name)
+	assert.Equal(t, map[string]interface{}{
+		"connector.class":          SourceClass,
+		"connection.url":           "jdbc:postgresql://db:5432/orders",
+		"connection.user":          "connect",
+		"connection.password":      "secret",
+		"mode":                     "timestamp+incrementing",
+		"incrementing.column.name": "id",
+		"timestamp.column.name":    "updated_at,created_at",
+		"table.whitelist":          "orders,order_lines",
+		"topic.prefix":             "db-",
+		"poll.interval.ms":         "30000",
+	}, request.Config)
+}
+
+func TestSink(t *testing.T) {
+	request, err := Sink{
+		Name:          "orders-sink",
+		Connection:    connection,
+		Topics:        []string{"orders"},
+		InsertMode:    InsertUpsert,
+		PKMode:        PKRecordKey,
+		PKFields:      []string{"id"},
+		AutoCreate:    connectors.Bool(true),
+		AutoEvolve:    connectors.Bool(false),
+		DeleteEnabled: connectors.Bool(true),
+		Extra:         map[string]interface{}{"batch.size": 500},
+	}.Request()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"connector.class":     SinkClass,
+		"connection.url":      "jdbc:postgresql://db:5432/orders",
+		"connection.user":     "connect",
+		"connection.password": "secret",
+		"topics":              "orders",
+		"insert.mode":         "upsert",
+		"pk.mode":             "record_key",
+		"pk.fields":           "id",
+		"auto.create":         "true",
+		"auto.evolve":         "false",
+		"delete.enabled":      "true",
+		"batch.size":          "500",
+	}, request.Config)
+}
+
+func TestValidation(t *testing.T) {
+	for _, test := range []struct {
+		builder interface {
+			Request() (*connect.ConnectorRequest, error)
+		}
+		expected string
+	}{
+		{Source{Name: "s", Connection: connection, TopicPrefix: "db-"},
+			"jdbc source config error: mode is required"},
+		{Source{Name: "s", Connection: connection, TopicPrefix: "db-", Mode: ModeTimestampIncrementing},
+			"jdbc source config error: incrementing.column.name is required; timestamp.column.name is required"},
+		{Source{Name: "s", Connection: connection, TopicPrefix: "db-", Mode: ModeBulk, Query: "SELECT 1", TableWhitelist: []string{"a"}},
+			"jdbc source config error: query cannot be set with table.whitelist or table.blacklist"},
+		{Sink{Name: "s", Connection: connection, InsertMode: InsertUpsert},
+			"jdbc sink config error: topics is required; insert.mode upsert requires a pk.mode other than none"},
+		{Sink{Name: "s", Connection: connection, Topics: []string{"a"}, TopicsRegex: "a.*", PKMode: PKKafka, PKFields: []string{"topic"}},
+			"jdbc sink config error: topics and topics.regex cannot be set together; pk.fields must name the topic, partition and offset columns with pk.mode kafka"},
+		{Sink{Name: "s", Connection: connection, Topics: []string{"a"}, PKMode: PKRecordValue, DeleteEnabled: connectors.Bool(true)},
+			"jdbc sink config error: delete.enabled requires pk.mode record_key"},
+	} {
+		_, err := test.builder.Request()
+		assert.IsType(t, &connectors.ConfigError{}, err)
+		assert.EqualError(t, err, test.expected)
+	}
+}
@@ -0,0 +1,252 @@
+// Package mirror builds the three MirrorMaker 2 connectors replicating a source cluster to a target cluster
+// from a Connect cluster, and creates or updates them as one unit
+//
+//	mm2 := mirror.MirrorMaker{
+//		Source: mirror.Cluster{Alias: "dc1", BootstrapServers: "dc1-kafka:9092"},
+//		Target: mirror.Cluster{Alias: "dc2", BootstrapServers: "dc2-kafka:9092"},
+//		Topics: []string{"users", "orders-.*"},
+//		Groups: []string{".*"},
+//	}
+//	err := mm2.Apply(client)
+package mirror
+
+import (
+	"strconv"
+	"time"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+)
+
+// connector classes
+const (
+	SourceClass     = "org.apache.kafka.connect.mirror.MirrorSourceConnector"
+	CheckpointClass = "org.apache.kafka.connect.mirror.MirrorCheckpointConnector"
+	HeartbeatClass  = "org.apache.kafka.connect.mirror.MirrorHeartbeatConnector"
+)
+
+// replication policies, the default one prefixes the remote topics with the source alias
+const (
+	DefaultReplicationPolicy  = "org.apache.kafka.connect.mirror.DefaultReplicationPolicy"
+	IdentityReplicationPolicy = "org.apache.kafka.connect.mirror.IdentityReplicationPolicy"
+)
+
+const byteArrayConverter = "org.apache.kafka.connect.converters.ByteArrayConverter"
+
+// Cluster is a kafka cluster replicated from or to
+type Cluster struct {
+	Alias            string
+	BootstrapServers string
+	// Config are the client settings of the cluster, e.g. security.protocol, set with the source.cluster. or target.cluster. prefix
+	Config map[string]string
+}
+
+func (c Cluster) set(config connectors.Config, prefix string) {
+	config.Set(prefix+".cluster.alias", c.Alias)
+	config.Set(prefix+".cluster.bootstrap.servers", c.BootstrapServers)
+	for key, value := range c.Config {
+		config.Set(prefix+".cluster."+key, value)
+	}
+}
+
+// MirrorMaker is the replication of topics and consumer group offsets from Source to Target.
+// The connectors must run on a Connect cluster backed by Target
+type MirrorMaker struct {
+	Source Cluster
+	Target Cluster
+	// NamePrefix is the prefix of the connector names, mm2-<source>-<target> by default
+	NamePrefix string
+	// Topics and TopicsExclude are regular expressions on the replicated topics, every topic is replicated when Topics is empty
+	Topics        []string
+	TopicsExclude []string
+	// Groups and GroupsExclude are regular expressions on the consumer groups whose offsets are checkpointed
+	Groups        []string
+	GroupsExclude []string
+	// ReplicationPolicy is the class naming the remote topics, DefaultReplicationPolicy when empty
+	ReplicationPolicy string
+	// ReplicationFactor is the replication factor of the remote topics and of the internal topics
+	ReplicationFactor int
+	// SyncGroupOffsets translates the checkpointed offsets into the consumer groups of Target
+	SyncGroupOffsets bool
+	// SyncTopicConfigs and SyncTopicACLs are enabled by the connector by default
+	SyncTopicConfigs *bool
+	SyncTopicACLs    *bool
+	// RefreshTopicsInterval, EmitCheckpointsInterval and EmitHeartbeatsInterval keep the connector defaults when zero
+	RefreshTopicsInterval   time.Duration
+	EmitCheckpointsInterval time.Duration
+	EmitHeartbeatsInterval  time.Duration
+	TasksMax                int
+	// Extra are set as is on the three connectors and override the typed fields
+	Extra map[string]interface{}
+}
+
+// Names returns the names of the source, checkpoint and heartbeat connectors
+func (m MirrorMaker) Names() (source, checkpoint, heartbeat string) {
+	prefix := m.NamePrefix
+	if prefix == "" {
+		prefix = "mm2-" + m.Source.Alias + "-" + m.Target.Alias
+	}
+	return prefix + "-source", prefix + "-checkpoint", prefix + "-heartbeat"
+}
+
+// Requests builds the source, checkpoint and heartbeat connector requests, sharing the aliases and the replication policy.
+// It returns a *connectors.ConfigError when the options are missing or conflicting
+func (m MirrorMaker) Requests() ([]connect.ConnectorRequest, error) {
+	check := connectors.NewCheck("mirror maker")
+	check.Required("source.cluster.alias", m.Source.Alias != "")
+	check.Required("target.cluster.alias", m.Target.Alias != "")
+	check.Required("source.cluster.bootstrap.servers", m.Source.BootstrapServers != "")
+	check.Required("target.cluster.bootstrap.servers", m.Target.BootstrapServers != "")
+	if m.Source.Alias != "" && m.Source.Alias == m.Target.Alias {
+		check.Errorf("source.cluster.alias and target.cluster.alias must differ")
+	}
+	if m.ReplicationFactor < 0 || m.TasksMax < 0 {
+		check.Errorf("replication.factor and tasks.max cannot be negative")
+	}
+	if err := check.Err(); err != nil {
+		return nil, err
+	}
+
+	sourceName, checkpointName, heartbeatName := m.Names()
+	source := m.config(SourceClass)
+	source.SetList("topics", m.Topics)
+	source.SetList("topics.exclude", m.TopicsExclude)
+	source.SetInt("replication.factor", m.ReplicationFactor)
+	source.SetInt("offset-syncs.topic.replication.factor", m.ReplicationFactor)
+	source.SetBool("sync.topic.configs.enabled", m.SyncTopicConfigs)
+	source.SetBool("sync.topic.acls.enabled", m.SyncTopicACLs)
+	setSeconds(source, "refresh.topics.interval.seconds", m.RefreshTopicsInterval)
+
+	checkpoint := m.config(CheckpointClass)
+	// the checkpoints only cover the groups consuming replicated topics
+	checkpoint.SetList("topics", m.Topics)
+	checkpoint.SetList("topics.exclude", m.TopicsExclude)
+	checkpoint.SetList("groups", m.Groups)
+	checkpoint.SetList("groups.exclude", m.GroupsExclude)
+	checkpoint.SetInt("checkpoints.topic.replication.factor", m.ReplicationFactor)
+	if m.SyncGroupOffsets {
+		checkpoint["sync.group.offsets.enabled"] = "true"
+	}
+	setSeconds(checkpoint, "emit.checkpoints.interval.seconds", m.EmitCheckpointsInterval)
+
+	heartbeat := m.config(HeartbeatClass)
+	heartbeat.SetInt("heartbeats.topic.replication.factor", m.ReplicationFactor)
+	setSeconds(heartbeat, "emit.heartbeats.interval.seconds", m.EmitHeartbeatsInterval)
+
+	requests := []connect.ConnectorRequest{
+		{Name: sourceName, Config: source},
+		{Name: checkpointName, Config: checkpoint},
+		{Name: heartbeatName, Config: heartbeat},
+	}
+	for _, request := range requests {
+		connectors.Config(request.Config).Merge(m.Extra)
+	}
+	return requests, nil
+}
+
+// config returns the settings shared by the three connectors
+func (m MirrorMaker) config(class string) connectors.Config {
+	config := connectors.Config{
+		"connector.class": class,
+		"key.converter":   byteArrayConverter,
+		"value.converter": byteArrayConverter,
+	}
+	m.Source.set(config, "source")
+	m.Target.set(config, "target")
+	config.Set("replication.policy.class", m.ReplicationPolicy)
+	config.SetInt("tasks.max", m.TasksMax)
+	return config
+}
+
+// Apply creates or updates the three connectors, the ones whose config is unchanged are left as is. When one of them cannot be applied, the ones already applied
+// are rolled back to their previous config, or deleted if they did not exist, and the error is returned
+func (m MirrorMaker) Apply(client connect.Connect) error {
+	requests, err := m.Requests()
+	if err != nil {
+		return err
+	}
+	existing, err := client.GetConnectors()
+	if err != nil {
+		return errors.Wrap(err, "mirror maker error: could not list connectors")
+	}
+	exists := make(map[string]bool, len(existing.Connectors))
+	for _, name := range existing.Connectors {
+		exists[name] = true
+	}
+
+	previous := make(map[string]map[string]interface{})
+	for _, request := range requests {
+		if exists[request.Name] {
+			config, err := client.GetConnectorConfig(request.Name)
+			if err != nil {
+				return errors.Wrapf(err, "mirror maker error: could not get config of %v", request.Name)
+			}
+			previous[request.Name] = config.Config
+		}
+	}
+
+	var applied []connect.ConnectorRequest
+	for _, request := range requests {
+		if config, ok := previous[request.Name]; ok && connect.ConfigEqual(request.Config, config) {
+			logger.Debugf("Mirror maker connector %v is unchanged", request.Name)
+			continue
+		}
+		if _, err := client.UpdateConnectorConfig(request); err != nil {
+			err = errors.Wrapf(err, "mirror maker error: could not apply %v", request.Name)
+			if rollbackErr := rollback(client, applied, previous); rollbackErr != nil {
+				return errors.Wrapf(err, "rollback failed: %v", rollbackErr)
+			}
+			return err
+		}
+		applied = append(applied, request)
+		logger.Infof("Applied mirror maker connector %v", request.Name)
+	}
+	return nil
+}
+
+// Delete deletes the three connectors, ignoring the ones that do not exist
+func (m MirrorMaker) Delete(client connect.Connect) error {
+	existing, err := client.GetConnectors()
+	if err != nil {
+		return errors.Wrap(err, "mirror maker error: could not list connectors")
+	}
+	sourceName, checkpointName, heartbeatName := m.Names()
+	for _, name := range existing.Connectors {
+		if name != sourceName && name != checkpointName && name != heartbeatName {
+			continue
+		}
+		if _, err := client.DeleteConnector(name); err != nil {
+			return errors.Wrapf(err, "mirror maker error: could not delete %v", name)
+		}
+	}
+	return nil
+}
+
+// rollback restores the previous config of the applied connectors, deleting the ones that were created
+func rollback(client connect.Connect, applied []connect.ConnectorRequest, previous map[string]map[string]interface{}) error {
+	var failed []string
+	for _, request := range applied {
+		var err error
+		if config, ok := previous[request.Name]; ok {
+			_, err = client.UpdateConnectorConfig(connect.ConnectorRequest{Name: request.Name, Config: config})
+		} else {
+			_, err = client.DeleteConnector(request.Name)
+		}
+		if err != nil {
+			logger.WithError(err).Errorf("Could not roll back mirror maker connector %v", request.Name)
+			failed = append(failed, request.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("could not roll back %v", failed)
+	}
+	return nil
+}
+
+func setSeconds(config connectors.Config, key string, value time.Duration) {
+	if value != 0 {
+		config[key] = strconv.FormatInt(int64(value/time.Second), 10)
+	}
+}
@@ -0,0 +1,132 @@
+package mirror
+
+import (
+	"testing"
+	"time"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubConnect stores connector configs, failing the update of one connector
+type stubConnect struct {
+	connect.Connect
+	configs map[string]map[string]interface{}
+	failing string
+	calls   []string
+}
+
+func (s *stubConnect) GetConnectors() (*connect.GetAllConnectorsResponse, error) {
+	response := &connect.GetAllConnectorsResponse{}
+	for name := range s.configs {
+		response.Connectors = append(response.Connectors, name)
+	}
+	return response, nil
+}
+
+func (s *stubConnect) GetConnectorConfig(name string) (*connect.GetConnectorConfigResponse, error) {
+	return &connect.GetConnectorConfigResponse{Config: s.configs[name]}, nil
+}
+
+func (s *stubConnect) UpdateConnectorConfig(request connect.ConnectorRequest) (*connect.ConnectorResponse, error) {
+	s.calls = append(s.calls, "update "+request.Name)
+	if request.Name == s.failing {
+		return nil, errors.New("invalid config")
+	}
+	s.configs[request.Name] = request.Config
+	return &connect.ConnectorResponse{Name: request.Name, Config: request.Config}, nil
+}
+
+func (s *stubConnect) DeleteConnector(name string) (*connect.EmptyResponse, error) {
+	s.calls = append(s.calls, "delete "+name)
+	delete(s.configs, name)
+	return &connect.EmptyResponse{Code: 204}, nil
+}
+
+var mm2 = MirrorMaker{
+	Source:                 Cluster{Alias: "dc1", BootstrapServers: "dc1:9092", Config: map[string]string{"security.protocol": "SSL"}},
+	Target:                 Cluster{Alias: "dc2", BootstrapServers: "dc2:9092"},
+	Topics:                 []string{"users", "orders-.*"},
+	Groups:                 []string{".*"},
+	ReplicationFactor:      3,
+	SyncGroupOffsets:       true,
+	SyncTopicACLs:          connectors.Bool(false),
+	EmitHeartbeatsInterval: 5 * time.Second,
+}
+
+func TestMirrorMaker_Requests(t *testing.T) {
+	requests, err := mm2.Requests()
+	assert.NoError(t, err)
+	shared := map[string]interface{}{
+		"key.converter":                    byteArrayConverter,
+		"value.converter":                  byteArrayConverter,
+		"source.cluster.alias":             "dc1",
+		"source.cluster.bootstrap.servers": "dc1:9092",
+		"source.cluster.security.protocol": "SSL",
+		"target.cluster.alias":             "dc2",
+		"target.cluster.bootstrap.servers": "dc2:9092",
+	}
+	with := func(config map[string]interface{}) map[string]interface{} {
+		for key, value := range shared {
+			config[key] = value
+		}
+		return config
+	}
+	assert.Equal(t, []connect.ConnectorRequest{
+		{Name: "mm2-dc1-dc2-source", Config: with(map[string]interface{}{
+			"connector.class":                       SourceClass,
+			"topics":                                "users,orders-.*",
+			"replication.factor":                    "3",
+			"offset-syncs.topic.replication.factor": "3",
+			"sync.topic.acls.enabled":               "false",
+		})},
+		{Name: "mm2-dc1-dc2-checkpoint", Config: with(map[string]interface{}{
+			"connector.class":                      CheckpointClass,
+			"topics":                               "users,orders-.*",
+			"groups":                               ".*",
+			"checkpoints.topic.replication.factor": "3",
+			"sync.group.offsets.enabled":           "true",
+		})},
+		{Name: "mm2-dc1-dc2-heartbeat", Config: with(map[string]interface{}{
+			"connector.class":                     HeartbeatClass,
+			"heartbeats.topic.replication.factor": "3",
+			"emit.heartbeats.interval.seconds":    "5",
+		})},
+	}, requests)
+
+	_, err = MirrorMaker{Source: Cluster{Alias: "dc1", BootstrapServers: "dc1:9092"}, Target: Cluster{Alias: "dc1"}}.Requests()
+	assert.IsType(t, &connectors.ConfigError{}, err)
+	assert.EqualError(t, err, "mirror maker config error: target.cluster.bootstrap.servers is required; "+
+		"source.cluster.alias and target.cluster.alias must differ")
+}
+
+func TestMirrorMaker_Apply(t *testing.T) {
+	client := &stubConnect{configs: map[string]map[string]interface{}{}}
+	assert.NoError(t, mm2.Apply(client))
+	assert.Equal(t, []string{"update mm2-dc1-dc2-source", "update mm2-dc1-dc2-checkpoint", "update mm2-dc1-dc2-heartbeat"}, client.calls)
+
+	// applying the same connectors again does not restart them
+	client.calls = nil
+	assert.NoError(t, mm2.Apply(client))
+	assert.Empty(t, client.calls)
+
+	// the checkpoint connector is rolled back to its previous config, the new heartbeat connector is deleted
+	previous := client.configs["mm2-dc1-dc2-checkpoint"]
+	delete(client.configs, "mm2-dc1-dc2-heartbeat")
+	client.calls, client.failing = nil, "mm2-dc1-dc2-heartbeat"
+	update := mm2
+	update.Topics = []string{"users"}
+	err := update.Apply(client)
+	assert.EqualError(t, err, "mirror maker error: could not apply mm2-dc1-dc2-heartbeat: invalid config")
+	assert.Equal(t, []string{
+		"update mm2-dc1-dc2-source", "update mm2-dc1-dc2-checkpoint", "update mm2-dc1-dc2-heartbeat",
+		"update mm2-dc1-dc2-source", "update mm2-dc1-dc2-checkpoint",
+	}, client.calls)
+	assert.Equal(t, previous, client.configs["mm2-dc1-dc2-checkpoint"])
+
+	client.failing = ""
+	assert.NoError(t, mm2.Delete(client))
+	assert.Empty(t, client.configs)
+}
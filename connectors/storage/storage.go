@@ -0,0 +1,286 @@
+// Package storage builds the configs of the Confluent S3, GCS and Azure Blob Storage sink connectors
+//
+//	request, err := storage.S3{
+//		Common: storage.Common{
+//			Name:        "events-s3",
+//			Topics:      []string{"events"},
+//			Format:      storage.FormatParquet,
+//			Partitioner: storage.Hourly("UTC"),
+//			FlushSize:   10000,
+//		},
+//		Bucket: "data-lake",
+//		Region: "eu-west-1",
+//	}.Request()
+package storage
+
+import (
+	"time"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+)
+
+// connector classes
+const (
+	S3Class        = "io.confluent.connect.s3.S3SinkConnector"
+	GCSClass       = "io.confluent.connect.gcs.GcsSinkConnector"
+	AzureBlobClass = "io.confluent.connect.azure.blob.AzureBlobStorageSinkConnector"
+)
+
+// Format is the format of the written objects, each connector has its own format classes
+type Format string
+
+const (
+	FormatAvro      Format = "avro"
+	FormatJSON      Format = "json"
+	FormatParquet   Format = "parquet"
+	FormatByteArray Format = "bytearray"
+)
+
+// class returns the format class in the format package of a connector, e.g. io.confluent.connect.s3.format
+func (f Format) class(pkg string) string {
+	switch f {
+	case FormatAvro:
+		return pkg + ".avro.AvroFormat"
+	case FormatJSON:
+		return pkg + ".json.JsonFormat"
+	case FormatParquet:
+		return pkg + ".parquet.ParquetFormat"
+	case FormatByteArray:
+		return pkg + ".bytearray.ByteArrayFormat"
+	}
+	return ""
+}
+
+// PartitionerClass is the class that maps the records to object paths
+type PartitionerClass string
+
+const (
+	DefaultPartitioner   PartitionerClass = "io.confluent.connect.storage.partitioner.DefaultPartitioner"
+	FieldPartitioner     PartitionerClass = "io.confluent.connect.storage.partitioner.FieldPartitioner"
+	TimeBasedPartitioner PartitionerClass = "io.confluent.connect.storage.partitioner.TimeBasedPartitioner"
+	DailyPartitioner     PartitionerClass = "io.confluent.connect.storage.partitioner.DailyPartitioner"
+	HourlyPartitioner    PartitionerClass = "io.confluent.connect.storage.partitioner.HourlyPartitioner"
+)
+
+// timeBased reports whether the partitioner partitions on time and needs a locale and a timezone
+func (p PartitionerClass) timeBased() bool {
+	return p == TimeBasedPartitioner || p == DailyPartitioner || p == HourlyPartitioner
+}
+
+// TimestampExtractor is where the time based partitioners take the time of a record from
+type TimestampExtractor string
+
+const (
+	ExtractWallclock   TimestampExtractor = "Wallclock"
+	ExtractRecord      TimestampExtractor = "Record"
+	ExtractRecordField TimestampExtractor = "RecordField"
+)
+
+// Partitioner maps the records to object paths, the DefaultPartitioner is used when Class is empty
+type Partitioner struct {
+	Class PartitionerClass
+	// FieldNames are the record fields of the FieldPartitioner
+	FieldNames []string
+	// PathFormat is the joda-time pattern of the TimeBasedPartitioner, e.g. 'year'=YYYY/'month'=MM
+	PathFormat string
+	// Duration is the time covered by a partition of the TimeBasedPartitioner
+	Duration time.Duration
+	// Locale and Timezone are required by the time based partitioners, e.g. en-US and UTC
+	Locale   string
+	Timezone string
+	// TimestampExtractor defaults to Wallclock, TimestampField is the field used by ExtractRecordField
+	TimestampExtractor TimestampExtractor
+	TimestampField     string
+}
+
+// TimeBased partitions on the time of the records with the path format, each partition covering the duration
+func TimeBased(pathFormat string, duration time.Duration, timezone string) Partitioner {
+	return Partitioner{
+		Class:      TimeBasedPartitioner,
+		PathFormat: pathFormat,
+		Duration:   duration,
+		Locale:     "en-US",
+		Timezone:   timezone,
+	}
+}
+
+// Hourly partitions on the time of the records by hour, with paths like year=2024/month=01/day=31/hour=23
+func Hourly(timezone string) Partitioner {
+	return TimeBased("'year'=YYYY/'month'=MM/'day'=dd/'hour'=HH", time.Hour, timezone)
+}
+
+// Daily partitions on the time of the records by day, with paths like year=2024/month=01/day=31
+func Daily(timezone string) Partitioner {
+	return TimeBased("'year'=YYYY/'month'=MM/'day'=dd", 24*time.Hour, timezone)
+}
+
+// Field partitions on the values of record fields
+func Field(names ...string) Partitioner {
+	return Partitioner{Class: FieldPartitioner, FieldNames: names}
+}
+
+// RecordField makes the partitioner use the time of a record field instead of the wall clock
+func (p Partitioner) RecordField(field string) Partitioner {
+	p.TimestampExtractor = ExtractRecordField
+	p.TimestampField = field
+	return p
+}
+
+func (p Partitioner) set(config connectors.Config) {
+	config.Set("partitioner.class", string(p.Class))
+	config.SetList("partition.field.name", p.FieldNames)
+	config.Set("path.format", p.PathFormat)
+	config.SetMillis("partition.duration.ms", p.Duration)
+	config.Set("locale", p.Locale)
+	config.Set("timezone", p.Timezone)
+	config.Set("timestamp.extractor", string(p.TimestampExtractor))
+	config.Set("timestamp.field", p.TimestampField)
+}
+
+func (p Partitioner) check(check *connectors.Check) {
+	switch p.Class {
+	case "", DefaultPartitioner:
+	case FieldPartitioner:
+		check.Required("partition.field.name", len(p.FieldNames) > 0)
+	case TimeBasedPartitioner:
+		if p.PathFormat == "" || p.Duration <= 0 {
+			check.Errorf("TimeBasedPartitioner requires path.format and partition.duration.ms")
+		}
+	}
+	if p.Class.timeBased() {
+		check.Required("locale", p.Locale != "")
+		check.Required("timezone", p.Timezone != "")
+	}
+	switch p.TimestampExtractor {
+	case "", ExtractWallclock, ExtractRecord:
+	case ExtractRecordField:
+		check.Required("timestamp.field", p.TimestampField != "")
+	default:
+		check.Errorf("timestamp.extractor %v is not supported", p.TimestampExtractor)
+	}
+}
+
+// Common are the settings of every object-store sink connector
+type Common struct {
+	Name string
+	// Topics or TopicsRegex are the consumed topics, exactly one of them is required
+	Topics      []string
+	TopicsRegex string
+	// TopicsDir is the top level directory of the objects
+	TopicsDir   string
+	Format      Format
+	Partitioner Partitioner
+	// FlushSize is the number of records written to an object before it is committed
+	FlushSize int
+	// RotateInterval commits the objects on the time of the records, RotateScheduleInterval on the wall clock
+	RotateInterval         time.Duration
+	RotateScheduleInterval time.Duration
+	TasksMax               int
+	// Extra are set as is and override the typed fields
+	Extra map[string]interface{}
+}
+
+func (c Common) config(class, formatPackage string) connectors.Config {
+	config := connectors.Config{"connector.class": class}
+	config.SetList("topics", c.Topics)
+	config.Set("topics.regex", c.TopicsRegex)
+	config.Set("topics.dir", c.TopicsDir)
+	config.Set("format.class", c.Format.class(formatPackage))
+	c.Partitioner.set(config)
+	config.SetInt("flush.size", c.FlushSize)
+	config.SetMillis("rotate.interval.ms", c.RotateInterval)
+	config.SetMillis("rotate.schedule.interval.ms", c.RotateScheduleInterval)
+	config.SetInt("tasks.max", c.TasksMax)
+	return config
+}
+
+func (c Common) check(connector string) *connectors.Check {
+	check := connectors.NewCheck(connector)
+	check.Required("name", c.Name != "")
+	if len(c.Topics) > 0 && c.TopicsRegex != "" {
+		check.Errorf("topics and topics.regex cannot be set together")
+	}
+	check.Required("topics", len(c.Topics) > 0 || c.TopicsRegex != "")
+	if c.Format == "" {
+		check.Required("format.class", false)
+	} else if c.Format.class("") == "" {
+		check.Errorf("format %v is not supported", c.Format)
+	}
+	check.Required("flush.size", c.FlushSize > 0)
+	c.Partitioner.check(check)
+	if c.RotateInterval < 0 || c.RotateScheduleInterval < 0 {
+		check.Errorf("rotate intervals cannot be negative")
+	}
+	if c.RotateScheduleInterval > 0 && c.Partitioner.Timezone == "" {
+		check.Errorf("rotate.schedule.interval.ms requires timezone")
+	}
+	return check
+}
+
+func (c Common) request(config connectors.Config, check *connectors.Check) (*connect.ConnectorRequest, error) {
+	if err := check.Err(); err != nil {
+		return nil, err
+	}
+	config.Merge(c.Extra)
+	return &connect.ConnectorRequest{Name: c.Name, Config: config}, nil
+}
+
+// S3 is the Amazon S3 sink connector, the credentials come from the default AWS provider chain unless set in Extra
+type S3 struct {
+	Common
+	Bucket string
+	Region string
+}
+
+// Request builds the connector request, it returns a *connectors.ConfigError when the options are missing or conflicting
+func (s S3) Request() (*connect.ConnectorRequest, error) {
+	check := s.check("s3 sink")
+	check.Required("s3.bucket.name", s.Bucket != "")
+
+	config := s.config(S3Class, "io.confluent.connect.s3.format")
+	config["storage.class"] = "io.confluent.connect.s3.storage.S3Storage"
+	config.Set("s3.bucket.name", s.Bucket)
+	config.Set("s3.region", s.Region)
+	return s.request(config, check)
+}
+
+// GCS is the Google Cloud Storage sink connector
+type GCS struct {
+	Common
+	Bucket string
+	// CredentialsPath is the service account key file, the default credentials are used when empty
+	CredentialsPath string
+}
+
+// Request builds the connector request, it returns a *connectors.ConfigError when the options are missing or conflicting
+func (g GCS) Request() (*connect.ConnectorRequest, error) {
+	check := g.check("gcs sink")
+	check.Required("gcs.bucket.name", g.Bucket != "")
+
+	config := g.config(GCSClass, "io.confluent.connect.gcs.format")
+	config.Set("gcs.bucket.name", g.Bucket)
+	config.Set("gcs.credentials.path", g.CredentialsPath)
+	return g.request(config, check)
+}
+
+// AzureBlob is the Azure Blob Storage sink connector
+type AzureBlob struct {
+	Common
+	AccountName string
+	AccountKey  string
+	Container   string
+}
+
+// Request builds the connector request, it returns a *connectors.ConfigError when the options are missing or conflicting
+func (a AzureBlob) Request() (*connect.ConnectorRequest, error) {
+	check := a.check("azure blob sink")
+	check.Required("azblob.account.name", a.AccountName != "")
+	check.Required("azblob.container.name", a.Container != "")
+
+	config := a.config(AzureBlobClass, "io.confluent.connect.azure.blob.format")
+	config.Set("azblob.account.name", a.AccountName)
+	config.Set("azblob.account.key", a.AccountKey)
+	config.Set("azblob.container.name", a.Container)
+	return a.request(config, check)
+}
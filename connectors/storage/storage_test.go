@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3(t *testing.T) {
+	request, err := S3{
+		Common: Common{
+			Name:                   "events-s3",
+			Topics:                 []string{"events"},
+			Format:                 FormatParquet,
+			Partitioner:            Hourly("UTC").RecordField("created_at"),
+			FlushSize:              10000,
+			RotateScheduleInterval: 10 * time.Minute,
+		},
+		Bucket: "data-lake",
+		Region: "eu-west-1",
+	}.Request()
+	assert.NoError(t, err)
+	assert.Equal(t, "events-s3", request.Name)
+	assert.Equal(t, map[string]interface{}{
+		"connector.class":             S3Class,
+		"storage.class":               "io.confluent.connect.s3.storage.S3Storage",
+		"format.class":                "io.confluent.connect.s3.format.parquet.ParquetFormat",
+		"topics":                      "events",
+		"s3.bucket.name":              "data-lake",
+		"s3.region":                   "eu-west-1",
+		"flush.size":                  "10000",
+		"rotate.schedule.interval.ms": "600000",
+		"partitioner.class":           string(TimeBasedPartitioner),
+		"path.format":                 "'year'=YYYY/'month'=MM/'day'=dd/'hour'=HH",
+		"partition.duration.ms":       "3600000",
+		"locale":                      "en-US",
+		"timezone":                    "UTC",
+		"timestamp.extractor":         "RecordField",
+		"timestamp.field":             "created_at",
+	}, request.Config)
+}
+
+func TestGCSAndAzureBlob(t *testing.T) {
+	common := Common{Name: "orders", TopicsRegex: "orders-.*", Format: FormatAvro, Partitioner: Field("country"), FlushSize: 100}
+
+	request, err := GCS{Common: common, Bucket: "lake", CredentialsPath: "/secrets/gcs.json"}.Request()
+	assert.NoError(t, err)
+	assert.Equal(t, GCSClass, request.Config["connector.class"])
+	assert.Equal(t, "io.confluent.connect.gcs.format.avro.AvroFormat", request.Config["format.class"])
+	assert.Equal(t, "country", request.Config["partition.field.name"])
+
+	request, err = AzureBlob{Common: common, AccountName: "lake", Container: "orders"}.Request()
+	assert.NoError(t, err)
+	assert.Equal(t, "io.confluent.connect.azure.blob.format.avro.AvroFormat", request.Config["format.class"])
+	assert.Equal(t, "orders-.*", request.Config["topics.regex"])
+}
+
+func TestValidation(t *testing.T) {
+	_, err := S3{Common: Common{
+		Name:        "events-s3",
+		Topics:      []string{"events"},
+		Format:      "orc",
+		Partitioner: Partitioner{Class: TimeBasedPartitioner, TimestampExtractor: ExtractRecordField},
+	}}.Request()
+	assert.IsType(t, &connectors.ConfigError{}, err)
+	assert.EqualError(t, err, "s3 sink config error: format orc is not supported; flush.size is required; "+
+		"TimeBasedPartitioner requires path.format and partition.duration.ms; locale is required; timezone is required; "+
+		"timestamp.field is required; s3.bucket.name is required")
+
+	_, err = GCS{Common: Common{Name: "g", Topics: []string{"a"}, Format: FormatJSON, FlushSize: 1,
+		Partitioner: Field(), RotateScheduleInterval: time.Minute}}.Request()
+	assert.EqualError(t, err, "gcs sink config error: partition.field.name is required; "+
+		"rotate.schedule.interval.ms requires timezone; gcs.bucket.name is required")
+}
@@ -0,0 +1,334 @@
+package transforms
+
+import (
+	"strings"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+)
+
+// Step is a transform of the chain
+type Step struct {
+	Alias     string
+	Transform Transform
+	// Predicate is the alias of the predicate the transform is conditioned on, Negate applies it to the other records
+	Predicate string
+	Negate    bool
+}
+
+// NamedPredicate is a predicate of the chain
+type NamedPredicate struct {
+	Alias     string
+	Predicate Predicate
+}
+
+// Chain is the ordered transforms of a connector and the predicates they use
+type Chain struct {
+	Steps      []Step
+	Predicates []NamedPredicate
+}
+
+// NewChain creates an empty chain
+func NewChain() *Chain {
+	return &Chain{}
+}
+
+// Add appends a transform
+func (c *Chain) Add(alias string, transform Transform) *Chain {
+	c.Steps = append(c.Steps, Step{Alias: alias, Transform: transform})
+	return c
+}
+
+// AddIf appends a transform applied to the records matching the predicate, or to the others when negate is set
+func (c *Chain) AddIf(alias string, transform Transform, predicate string, negate bool) *Chain {
+	c.Steps = append(c.Steps, Step{Alias: alias, Transform: transform, Predicate: predicate, Negate: negate})
+	return c
+}
+
+// AddPredicate declares a predicate the transforms can be conditioned on
+func (c *Chain) AddPredicate(alias string, predicate Predicate) *Chain {
+	c.Predicates = append(c.Predicates, NamedPredicate{Alias: alias, Predicate: predicate})
+	return c
+}
+
+// Validate returns a *connectors.ConfigError when an alias is invalid or repeated, a predicate is unknown,
+// or a required setting of a transform is missing
+func (c *Chain) Validate() error {
+	check := connectors.NewCheck("transforms")
+	predicates := make(map[string]bool, len(c.Predicates))
+	for _, p := range c.Predicates {
+		checkAlias(check, "predicates", p.Alias, predicates)
+		checkRequired(check, "predicates."+p.Alias, p.Predicate)
+	}
+	aliases := make(map[string]bool, len(c.Steps))
+	for _, step := range c.Steps {
+		checkAlias(check, "transforms", step.Alias, aliases)
+		checkRequired(check, "transforms."+step.Alias, step.Transform)
+		if step.Predicate != "" && !predicates[step.Predicate] {
+			check.Errorf("transforms.%v.predicate %v is not declared", step.Alias, step.Predicate)
+		}
+		if step.Negate && step.Predicate == "" {
+			check.Errorf("transforms.%v.negate requires a predicate", step.Alias)
+		}
+	}
+	return check.Err()
+}
+
+func checkAlias(check *connectors.Check, kind, alias string, seen map[string]bool) {
+	switch {
+	case alias == "" || strings.ContainsAny(alias, ", "):
+		check.Errorf("%v alias %q is not valid", kind, alias)
+	case seen[alias]:
+		check.Errorf("%v alias %v is repeated", kind, alias)
+	}
+	seen[alias] = true
+}
+
+func checkRequired(check *connectors.Check, prefix string, plugin interface {
+	Class() string
+	Config() map[string]string
+}) {
+	if plugin == nil || plugin.Class() == "" {
+		check.Required(prefix+".type", false)
+		return
+	}
+	r, ok := plugin.(required)
+	if !ok {
+		return
+	}
+	config := plugin.Config()
+	for _, key := range r.required() {
+		check.Required(prefix+"."+key, config[key] != "")
+	}
+}
+
+// Config returns the transforms and predicates keys of the chain
+func (c *Chain) Config() (map[string]interface{}, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	config := make(map[string]interface{})
+	if len(c.Steps) > 0 {
+		aliases := make([]string, len(c.Steps))
+		for i, step := range c.Steps {
+			aliases[i] = step.Alias
+			prefix := "transforms." + step.Alias + "."
+			config[prefix+"type"] = step.Transform.Class()
+			for key, value := range step.Transform.Config() {
+				config[prefix+key] = value
+			}
+			if step.Predicate != "" {
+				config[prefix+"predicate"] = step.Predicate
+			}
+			if step.Negate {
+				config[prefix+"negate"] = "true"
+			}
+		}
+		config["transforms"] = strings.Join(aliases, ",")
+	}
+	if len(c.Predicates) > 0 {
+		aliases := make([]string, len(c.Predicates))
+		for i, p := range c.Predicates {
+			aliases[i] = p.Alias
+			prefix := "predicates." + p.Alias + "."
+			config[prefix+"type"] = p.Predicate.Class()
+			for key, value := range p.Predicate.Config() {
+				config[prefix+key] = value
+			}
+		}
+		config["predicates"] = strings.Join(aliases, ",")
+	}
+	return config, nil
+}
+
+// ApplyTo replaces the transforms and predicates of the connector config with the chain
+func (c *Chain) ApplyTo(config map[string]interface{}) error {
+	chain, err := c.Config()
+	if err != nil {
+		return err
+	}
+	for key := range config {
+		if isChainKey(key) {
+			delete(config, key)
+		}
+	}
+	for key, value := range chain {
+		config[key] = value
+	}
+	return nil
+}
+
+func isChainKey(key string) bool {
+	return key == "transforms" || key == "predicates" ||
+		strings.HasPrefix(key, "transforms.") || strings.HasPrefix(key, "predicates.")
+}
+
+// Parse reads the chain of a connector config. The built-in transforms and predicates are returned typed,
+// the others as a Custom
+func Parse(config map[string]interface{}) (*Chain, error) {
+	chain := NewChain()
+	for _, alias := range splitList(connect.NormalizeConfigValue(config["predicates"])) {
+		class, settings, err := plugin(config, "predicates."+alias+".")
+		if err != nil {
+			return nil, err
+		}
+		chain.AddPredicate(alias, parsePredicate(class, settings))
+	}
+	for _, alias := range splitList(connect.NormalizeConfigValue(config["transforms"])) {
+		class, settings, err := plugin(config, "transforms."+alias+".")
+		if err != nil {
+			return nil, err
+		}
+		predicate := settings["predicate"]
+		negate := strings.EqualFold(settings["negate"], "true")
+		delete(settings, "predicate")
+		delete(settings, "negate")
+		chain.AddIf(alias, parseTransform(class, settings), predicate, negate)
+	}
+	return chain, nil
+}
+
+// plugin returns the class and the settings of the transform or predicate with the prefix
+func plugin(config map[string]interface{}, prefix string) (string, map[string]string, error) {
+	class := connect.NormalizeConfigValue(config[prefix+"type"])
+	if class == "" {
+		return "", nil, &connectors.ConfigError{Connector: "transforms", Problems: []string{prefix + "type is required"}}
+	}
+	settings := make(map[string]string)
+	for key := range config {
+		if strings.HasPrefix(key, prefix) && key != prefix+"type" {
+			settings[strings.TrimPrefix(key, prefix)] = connect.NormalizeConfigValue(config[key])
+		}
+	}
+	return class, settings, nil
+}
+
+func parseTransform(class string, s map[string]string) Transform {
+	name, target := class, Target("")
+	if i := strings.LastIndexByte(class, '$'); i >= 0 {
+		name, target = class[:i], Target(class[i+1:])
+	}
+	if !strings.HasPrefix(name, transformsPackage) || (target != "" && target != Key && target != Value) {
+		return Custom{Type: class, Settings: s}
+	}
+
+	var transform Transform
+	var known []string
+	switch strings.TrimPrefix(name, transformsPackage) {
+	case "ReplaceField":
+		// exclude and include were named blacklist and whitelist before kafka 2.7
+		transform = ReplaceField{Target: target, Exclude: splitList(first(s, "exclude", "blacklist")),
+			Include: splitList(first(s, "include", "whitelist")), Renames: splitPairs(s["renames"])}
+		known = []string{"exclude", "blacklist", "include", "whitelist", "renames"}
+	case "InsertField":
+		transform = InsertField{Target: target, TopicField: s["topic.field"], PartitionField: s["partition.field"],
+			OffsetField: s["offset.field"], TimestampField: s["timestamp.field"],
+			StaticField: s["static.field"], StaticValue: s["static.value"]}
+		known = []string{"topic.field", "partition.field", "offset.field", "timestamp.field", "static.field", "static.value"}
+	case "MaskField":
+		transform = MaskField{Target: target, Fields: splitList(s["fields"]), Replacement: s["replacement"]}
+		known = []string{"fields", "replacement"}
+	case "RegexRouter":
+		transform = RegexRouter{Regex: s["regex"], Replacement: s["replacement"]}
+		known = []string{"regex", "replacement"}
+	case "TimestampRouter":
+		transform = TimestampRouter{TopicFormat: s["topic.format"], TimestampFormat: s["timestamp.format"]}
+		known = []string{"topic.format", "timestamp.format"}
+	case "ExtractField":
+		transform = ExtractField{Target: target, Field: s["field"]}
+		known = []string{"field"}
+	case "Cast":
+		var spec []CastSpec
+		for _, item := range splitList(s["spec"]) {
+			if i := strings.IndexByte(item, ':'); i >= 0 {
+				spec = append(spec, CastSpec{Field: strings.TrimSpace(item[:i]), Type: strings.TrimSpace(item[i+1:])})
+			} else {
+				spec = append(spec, CastSpec{Type: item})
+			}
+		}
+		transform = Cast{Target: target, Spec: spec}
+		known = []string{"spec"}
+	case "Flatten":
+		transform = Flatten{Target: target, Delimiter: s["delimiter"]}
+		known = []string{"delimiter"}
+	case "Filter":
+		transform = Filter{}
+	case "HoistField":
+		transform = HoistField{Target: target, Field: s["field"]}
+		known = []string{"field"}
+	case "ValueToKey":
+		transform = ValueToKey{Fields: splitList(s["fields"])}
+		known = []string{"fields"}
+	default:
+		return Custom{Type: class, Settings: s}
+	}
+	// settings the typed transform does not have, e.g. replace.null.with.default, are kept by falling back to Custom
+	if !onlyKnown(s, known) {
+		return Custom{Type: class, Settings: s}
+	}
+	return transform
+}
+
+func parsePredicate(class string, s map[string]string) Predicate {
+	switch class {
+	case predicatesPackage + "TopicNameMatches":
+		if onlyKnown(s, []string{"pattern"}) {
+			return TopicNameMatches{Pattern: s["pattern"]}
+		}
+	case predicatesPackage + "HasHeaderKey":
+		if onlyKnown(s, []string{"name"}) {
+			return HasHeaderKey{Name: s["name"]}
+		}
+	case predicatesPackage + "RecordIsTombstone":
+		if len(s) == 0 {
+			return RecordIsTombstone{}
+		}
+	}
+	return Custom{Type: class, Settings: s}
+}
+
+func onlyKnown(settings map[string]string, known []string) bool {
+	for key := range settings {
+		found := false
+		for _, k := range known {
+			found = found || k == key
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func first(settings map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if settings[key] != "" {
+			return settings[key]
+		}
+	}
+	return ""
+}
+
+func splitList(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func splitPairs(list string) map[string]string {
+	pairs := splitList(list)
+	if len(pairs) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		if i := strings.IndexByte(pair, ':'); i >= 0 {
+			values[pair[:i]] = pair[i+1:]
+		}
+	}
+	return values
+}
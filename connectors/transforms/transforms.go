@@ -0,0 +1,312 @@
+// Package transforms builds the single message transform chain of a connector config, and parses it back
+//
+//	chain := transforms.NewChain().
+//		AddPredicate("isTombstone", transforms.RecordIsTombstone{}).
+//		AddIf("dropTombstones", transforms.Filter{}, "isTombstone", false).
+//		Add("key", transforms.ValueToKey{Fields: []string{"id"}}).
+//		Add("route", transforms.RegexRouter{Regex: "(.*)-raw", Replacement: "$1"})
+//	err := chain.ApplyTo(request.Config)
+package transforms
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	transformsPackage = "org.apache.kafka.connect.transforms."
+	predicatesPackage = "org.apache.kafka.connect.transforms.predicates."
+)
+
+// Transform is a single message transform, the built-in ones are typed and any other is a Custom
+type Transform interface {
+	// Class is the fully qualified class, including the $Key or $Value suffix of the transforms applying to either
+	Class() string
+	// Config are the settings of the transform, without the transforms.<alias>. prefix
+	Config() map[string]string
+}
+
+// Predicate is a condition on the records a transform applies to
+type Predicate interface {
+	Class() string
+	Config() map[string]string
+}
+
+// required is implemented by the transforms and predicates with settings that must be set
+type required interface {
+	required() []string
+}
+
+// Target is the part of the record a transform applies to
+type Target string
+
+const (
+	Value Target = "Value"
+	Key   Target = "Key"
+)
+
+func (t Target) class(name string) string {
+	if t == "" {
+		t = Value
+	}
+	return transformsPackage + name + "$" + string(t)
+}
+
+// Custom is a transform or a predicate that is not built into kafka, or whose settings are kept as is
+type Custom struct {
+	Type     string
+	Settings map[string]string
+}
+
+func (c Custom) Class() string             { return c.Type }
+func (c Custom) Config() map[string]string { return c.Settings }
+
+// ReplaceField filters and renames fields
+type ReplaceField struct {
+	Target  Target
+	Exclude []string
+	Include []string
+	// Renames maps the old field names to the new ones
+	Renames map[string]string
+}
+
+func (t ReplaceField) Class() string { return t.Target.class("ReplaceField") }
+
+func (t ReplaceField) Config() map[string]string {
+	config := settings{}
+	config.list("exclude", t.Exclude)
+	config.list("include", t.Include)
+	config.pairs("renames", t.Renames)
+	return config
+}
+
+// InsertField inserts record metadata or a static value as fields
+type InsertField struct {
+	Target         Target
+	TopicField     string
+	PartitionField string
+	OffsetField    string
+	TimestampField string
+	StaticField    string
+	StaticValue    string
+}
+
+func (t InsertField) Class() string { return t.Target.class("InsertField") }
+
+func (t InsertField) Config() map[string]string {
+	config := settings{}
+	config.set("topic.field", t.TopicField)
+	config.set("partition.field", t.PartitionField)
+	config.set("offset.field", t.OffsetField)
+	config.set("timestamp.field", t.TimestampField)
+	config.set("static.field", t.StaticField)
+	config.set("static.value", t.StaticValue)
+	return config
+}
+
+// MaskField replaces fields with the null value of their type, or with Replacement
+type MaskField struct {
+	Target      Target
+	Fields      []string
+	Replacement string
+}
+
+func (t MaskField) Class() string { return t.Target.class("MaskField") }
+
+func (t MaskField) Config() map[string]string {
+	config := settings{}
+	config.list("fields", t.Fields)
+	config.set("replacement", t.Replacement)
+	return config
+}
+
+func (t MaskField) required() []string { return []string{"fields"} }
+
+// RegexRouter renames the topic of the records matching the regular expression
+type RegexRouter struct {
+	Regex       string
+	Replacement string
+}
+
+func (t RegexRouter) Class() string { return transformsPackage + "RegexRouter" }
+
+func (t RegexRouter) Config() map[string]string {
+	config := settings{}
+	config.set("regex", t.Regex)
+	config.set("replacement", t.Replacement)
+	return config
+}
+
+func (t RegexRouter) required() []string { return []string{"regex", "replacement"} }
+
+// TimestampRouter renames the topic after the timestamp of the records, e.g. ${topic}-${timestamp}
+type TimestampRouter struct {
+	TopicFormat     string
+	TimestampFormat string
+}
+
+func (t TimestampRouter) Class() string { return transformsPackage + "TimestampRouter" }
+
+func (t TimestampRouter) Config() map[string]string {
+	config := settings{}
+	config.set("topic.format", t.TopicFormat)
+	config.set("timestamp.format", t.TimestampFormat)
+	return config
+}
+
+// ExtractField replaces the struct or map with one of its fields
+type ExtractField struct {
+	Target Target
+	Field  string
+}
+
+func (t ExtractField) Class() string { return t.Target.class("ExtractField") }
+
+func (t ExtractField) Config() map[string]string {
+	config := settings{}
+	config.set("field", t.Field)
+	return config
+}
+
+func (t ExtractField) required() []string { return []string{"field"} }
+
+// CastSpec casts a field, or the whole key or value when Field is empty, to a primitive type such as int64 or string
+type CastSpec struct {
+	Field string
+	Type  string
+}
+
+// Cast casts fields or the whole key or value
+type Cast struct {
+	Target Target
+	Spec   []CastSpec
+}
+
+func (t Cast) Class() string { return t.Target.class("Cast") }
+
+func (t Cast) Config() map[string]string {
+	specs := make([]string, len(t.Spec))
+	for i, spec := range t.Spec {
+		specs[i] = spec.Type
+		if spec.Field != "" {
+			specs[i] = spec.Field + ":" + spec.Type
+		}
+	}
+	config := settings{}
+	config.list("spec", specs)
+	return config
+}
+
+func (t Cast) required() []string { return []string{"spec"} }
+
+// Flatten flattens nested structs and maps, joining the field names with the delimiter
+type Flatten struct {
+	Target    Target
+	Delimiter string
+}
+
+func (t Flatten) Class() string { return t.Target.class("Flatten") }
+
+func (t Flatten) Config() map[string]string {
+	config := settings{}
+	config.set("delimiter", t.Delimiter)
+	return config
+}
+
+// Filter drops the records, it is used with a predicate
+type Filter struct{}
+
+func (t Filter) Class() string             { return transformsPackage + "Filter" }
+func (t Filter) Config() map[string]string { return settings{} }
+
+// HoistField wraps the key or value in a struct or map with a single field
+type HoistField struct {
+	Target Target
+	Field  string
+}
+
+func (t HoistField) Class() string { return t.Target.class("HoistField") }
+
+func (t HoistField) Config() map[string]string {
+	config := settings{}
+	config.set("field", t.Field)
+	return config
+}
+
+func (t HoistField) required() []string { return []string{"field"} }
+
+// ValueToKey replaces the key with a struct of fields of the value
+type ValueToKey struct {
+	Fields []string
+}
+
+func (t ValueToKey) Class() string { return transformsPackage + "ValueToKey" }
+
+func (t ValueToKey) Config() map[string]string {
+	config := settings{}
+	config.list("fields", t.Fields)
+	return config
+}
+
+func (t ValueToKey) required() []string { return []string{"fields"} }
+
+// TopicNameMatches matches the records whose topic matches the regular expression
+type TopicNameMatches struct {
+	Pattern string
+}
+
+func (p TopicNameMatches) Class() string { return predicatesPackage + "TopicNameMatches" }
+
+func (p TopicNameMatches) Config() map[string]string {
+	config := settings{}
+	config.set("pattern", p.Pattern)
+	return config
+}
+
+func (p TopicNameMatches) required() []string { return []string{"pattern"} }
+
+// HasHeaderKey matches the records with a header named Name
+type HasHeaderKey struct {
+	Name string
+}
+
+func (p HasHeaderKey) Class() string { return predicatesPackage + "HasHeaderKey" }
+
+func (p HasHeaderKey) Config() map[string]string {
+	config := settings{}
+	config.set("name", p.Name)
+	return config
+}
+
+func (p HasHeaderKey) required() []string { return []string{"name"} }
+
+// RecordIsTombstone matches the records with a null value
+type RecordIsTombstone struct{}
+
+func (p RecordIsTombstone) Class() string             { return predicatesPackage + "RecordIsTombstone" }
+func (p RecordIsTombstone) Config() map[string]string { return settings{} }
+
+// settings are the config of a transform, the setters skip empty values
+type settings map[string]string
+
+func (s settings) set(key, value string) {
+	if value != "" {
+		s[key] = value
+	}
+}
+
+func (s settings) list(key string, values []string) {
+	if len(values) > 0 {
+		s[key] = strings.Join(values, ",")
+	}
+}
+
+// pairs sets the map as a sorted list of key:value
+func (s settings) pairs(key string, values map[string]string) {
+	var pairs []string
+	for k, v := range values {
+		pairs = append(pairs, k+":"+v)
+	}
+	sort.Strings(pairs)
+	s.list(key, pairs)
+}
@@ -0,0 +1,106 @@
+package transforms
+
+import (
+	"testing"
+
+	"github.com/kevinsamoei/kafka-connect-go/connectors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain_Config(t *testing.T) {
+	chain := NewChain().
+		AddPredicate("isTombstone", RecordIsTombstone{}).
+		AddPredicate("isAudit", TopicNameMatches{Pattern: "audit-.*"}).
+		AddIf("dropTombstones", Filter{}, "isTombstone", false).
+		Add("rename", ReplaceField{Target: Value, Exclude: []string{"password"}, Renames: map[string]string{"uid": "user_id", "ts": "timestamp"}}).
+		AddIf("cast", Cast{Target: Value, Spec: []CastSpec{{Field: "amount", Type: "float64"}}}, "isAudit", true).
+		Add("key", ValueToKey{Fields: []string{"user_id"}}).
+		Add("extract", ExtractField{Target: Key, Field: "user_id"}).
+		Add("route", RegexRouter{Regex: "(.*)-raw", Replacement: "$1"})
+
+	config, err := chain.Config()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"transforms":                          "dropTombstones,rename,cast,key,extract,route",
+		"transforms.dropTombstones.type":      "org.apache.kafka.connect.transforms.Filter",
+		"transforms.dropTombstones.predicate": "isTombstone",
+		"transforms.rename.type":              "org.apache.kafka.connect.transforms.ReplaceField$Value",
+		"transforms.rename.exclude":           "password",
+		"transforms.rename.renames":           "ts:timestamp,uid:user_id",
+		"transforms.cast.type":                "org.apache.kafka.connect.transforms.Cast$Value",
+		"transforms.cast.spec":                "amount:float64",
+		"transforms.cast.predicate":           "isAudit",
+		"transforms.cast.negate":              "true",
+		"transforms.key.type":                 "org.apache.kafka.connect.transforms.ValueToKey",
+		"transforms.key.fields":               "user_id",
+		"transforms.extract.type":             "org.apache.kafka.connect.transforms.ExtractField$Key",
+		"transforms.extract.field":            "user_id",
+		"transforms.route.type":               "org.apache.kafka.connect.transforms.RegexRouter",
+		"transforms.route.regex":              "(.*)-raw",
+		"transforms.route.replacement":        "$1",
+		"predicates":                          "isTombstone,isAudit",
+		"predicates.isTombstone.type":         "org.apache.kafka.connect.transforms.predicates.RecordIsTombstone",
+		"predicates.isAudit.type":             "org.apache.kafka.connect.transforms.predicates.TopicNameMatches",
+		"predicates.isAudit.pattern":          "audit-.*",
+	}, config)
+
+	parsed, err := Parse(config)
+	assert.NoError(t, err)
+	assert.Equal(t, chain, parsed)
+}
+
+func TestChain_ApplyTo(t *testing.T) {
+	config := map[string]interface{}{
+		"connector.class":          "FileStreamSource",
+		"transforms":               "old",
+		"transforms.old.type":      "org.apache.kafka.connect.transforms.Flatten$Value",
+		"transforms.old.delimiter": "_",
+	}
+	err := NewChain().Add("hoist", HoistField{Field: "line"}).ApplyTo(config)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"connector.class":        "FileStreamSource",
+		"transforms":             "hoist",
+		"transforms.hoist.type":  "org.apache.kafka.connect.transforms.HoistField$Value",
+		"transforms.hoist.field": "line",
+	}, config)
+}
+
+func TestParse(t *testing.T) {
+	chain, err := Parse(map[string]interface{}{
+		"transforms":                                  "unwrap, mask,legacy,insert",
+		"transforms.unwrap.type":                      "io.debezium.transforms.ExtractNewRecordState",
+		"transforms.unwrap.drop.tombstones":           "false",
+		"transforms.mask.type":                        "org.apache.kafka.connect.transforms.MaskField$Value",
+		"transforms.mask.fields":                      "ssn, email",
+		"transforms.legacy.type":                      "org.apache.kafka.connect.transforms.ReplaceField$Value",
+		"transforms.legacy.blacklist":                 "password",
+		"transforms.insert.type":                      "org.apache.kafka.connect.transforms.InsertField$Value",
+		"transforms.insert.topic.field":               "topic",
+		"transforms.insert.replace.null.with.default": "false",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Step{
+		{Alias: "unwrap", Transform: Custom{Type: "io.debezium.transforms.ExtractNewRecordState", Settings: map[string]string{"drop.tombstones": "false"}}},
+		{Alias: "mask", Transform: MaskField{Target: Value, Fields: []string{"ssn", "email"}}},
+		{Alias: "legacy", Transform: ReplaceField{Target: Value, Exclude: []string{"password"}}},
+		{Alias: "insert", Transform: Custom{Type: "org.apache.kafka.connect.transforms.InsertField$Value",
+			Settings: map[string]string{"topic.field": "topic", "replace.null.with.default": "false"}}},
+	}, chain.Steps)
+
+	_, err = Parse(map[string]interface{}{"transforms": "missing"})
+	assert.EqualError(t, err, "transforms config error: transforms.missing.type is required")
+}
+
+func TestChain_Validate(t *testing.T) {
+	err := NewChain().
+		Add("route", RegexRouter{Regex: ".*"}).
+		Add("route", Flatten{}).
+		AddIf("drop", Filter{}, "isTombstone", false).
+		Add("bad alias", Custom{}).
+		Validate()
+	assert.IsType(t, &connectors.ConfigError{}, err)
+	assert.EqualError(t, err, "transforms config error: transforms.route.replacement is required; "+
+		"transforms alias route is repeated; transforms.drop.predicate isTombstone is not declared; "+
+		`transforms alias "bad alias" is not valid; transforms.bad alias.type is required`)
+}
@@ -0,0 +1,81 @@
+package connect
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ConfigChangeType describes how a single config key differs between the desired and the actual config
+type ConfigChangeType string
+
+const (
+	// ConfigAdded is a key that is in the desired config but not on the cluster
+	ConfigAdded ConfigChangeType = "added"
+	// ConfigChanged is a key whose value differs between the desired config and the cluster
+	ConfigChanged ConfigChangeType = "changed"
+	// ConfigRemoved is a key that is on the cluster but not in the desired config
+	ConfigRemoved ConfigChangeType = "removed"
+)
+
+// ConfigChange is the difference of a single key. Values are normalised to the string form stored by connect
+type ConfigChange struct {
+	Key  string           `json:"key"`
+	Type ConfigChangeType `json:"type"`
+	Old  string           `json:"old,omitempty"`
+	New  string           `json:"new,omitempty"`
+}
+
+// DiffConfig compares the desired config with the config returned by connect, values are compared after NormalizeConfigValue.
+// The name key that connect adds to every config is ignored unless it is part of the desired config.
+// Values of the keys that are sensitive for the DefaultRedactor are masked
+func DiffConfig(desired, actual map[string]interface{}) []ConfigChange {
+	var changes []ConfigChange
+	for key, value := range desired {
+		newValue := NormalizeConfigValue(value)
+		actualValue, ok := actual[key]
+		if !ok {
+			changes = append(changes, maskChange(ConfigChange{Key: key, Type: ConfigAdded, New: newValue}))
+			continue
+		}
+		oldValue := NormalizeConfigValue(actualValue)
+		if oldValue != newValue {
+			changes = append(changes, maskChange(ConfigChange{Key: key, Type: ConfigChanged, Old: oldValue, New: newValue}))
+		}
+	}
+	for key, value := range actual {
+		if _, ok := desired[key]; ok || key == "name" {
+			continue
+		}
+		changes = append(changes, maskChange(ConfigChange{Key: key, Type: ConfigRemoved, Old: NormalizeConfigValue(value)}))
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+func maskChange(change ConfigChange) ConfigChange {
+	if !DefaultRedactor.IsSensitive(change.Key) {
+		return change
+	}
+	if change.Old != "" {
+		change.Old = RedactedValue
+	}
+	if change.New != "" {
+		change.New = RedactedValue
+	}
+	return change
+}
+
+// String returns the change as a single line of a plan
+func (c ConfigChange) String() string {
+	switch c.Type {
+	case ConfigAdded:
+		return fmt.Sprintf("+ %v = %q", c.Key, c.New)
+	case ConfigRemoved:
+		return fmt.Sprintf("- %v = %q", c.Key, c.Old)
+	default:
+		return fmt.Sprintf("~ %v: %q => %q", c.Key, c.Old, c.New)
+	}
+}
@@ -0,0 +1,62 @@
+package connect
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffConfig(t *testing.T) {
+	desired := map[string]interface{}{
+		"connector.class":                    "A",
+		"confluent.topic.replication.factor": 1,
+		"provenance.header.enable":           true,
+		"tasks.max":                          "2",
+		"connection.password":                "new-secret",
+		"key.converter":                      "org.apache.kafka.connect.storage.StringConverter",
+	}
+	actual := map[string]interface{}{
+		"name":                               "test",
+		"connector.class":                    "A",
+		"confluent.topic.replication.factor": "1",
+		"provenance.header.enable":           "true",
+		"tasks.max":                          "1",
+		"connection.password":                "old-secret",
+		"topic.whitelist":                    "users",
+	}
+
+	assert.Equal(t, []ConfigChange{
+		{Key: "connection.password", Type: ConfigChanged, Old: RedactedValue, New: RedactedValue},
+		{Key: "key.converter", Type: ConfigAdded, New: "org.apache.kafka.connect.storage.StringConverter"},
+		{Key: "tasks.max", Type: ConfigChanged, Old: "1", New: "2"},
+		{Key: "topic.whitelist", Type: ConfigRemoved, Old: "users"},
+	}, DiffConfig(desired, actual))
+}
+
+func TestReconcilePlan_Render(t *testing.T) {
+	fake := newFakeConnect()
+	fake.add("drifted", map[string]interface{}{"connector.class": "A", "tasks.max": 1})
+	fake.add("unmanaged", map[string]interface{}{"connector.class": "A"})
+
+	reconciler := NewReconciler(fake)
+	reconciler.Prune = true
+	plan, err := reconciler.Plan([]ConnectorRequest{
+		{Name: "drifted", Config: map[string]interface{}{"connector.class": "A", "tasks.max": 2}},
+		{Name: "new", Config: map[string]interface{}{"connector.class": "B"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `~ connector drifted
+    ~ tasks.max: "1" => "2"
++ connector new
+    + connector.class = "B"
+- connector unmanaged
+Plan: 1 to create, 1 to update, 1 to delete, 0 unchanged.
+`, plan.String())
+
+	out, err := plan.JSON()
+	assert.NoError(t, err)
+	var decoded ReconcilePlan
+	assert.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, plan.Steps[0].Changes, decoded.Steps[0].Changes)
+}
@@ -0,0 +1,56 @@
+package connect
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// ErrExpandNotSupported is returned by GetConnectorsExpanded when the worker does not support the expand parameter
+var ErrExpandNotSupported = errors.New("connectors expand parameter is not supported by the worker")
+
+// APIError is returned when a kafka connect endpoint responds with an error status code
+type APIError struct {
+	ErrorResponse
+	// Operation is the client operation that failed, e.g. "get connector"
+	Operation  string
+	StatusCode int
+	// Body is the response body with its sensitive values masked
+	Body string
+}
+
+func newAPIError(operation string, resp *resty.Response) error {
+	apiErr := &APIError{
+		Operation:  operation,
+		StatusCode: resp.StatusCode(),
+		Body:       DefaultRedactor.RedactString(resp.String()),
+	}
+	if errResp, ok := resp.Error().(*ErrorResponse); ok && errResp != nil {
+		apiErr.ErrorResponse = *errResp
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v error: %v", e.Operation, e.Body)
+}
+
+// StatusCode returns the HTTP status code of an APIError, including wrapped ones, or 0 for any other error
+func StatusCode(err error) int {
+	if apiErr, ok := errors.Cause(err).(*APIError); ok {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether the error is a 404 returned by kafka connect
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether the error is a 409 returned by kafka connect, usually because a rebalance is in process
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
@@ -0,0 +1,65 @@
+package exporter
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ClientMetrics records the latency and the errors of the requests made by a connect client.
+// It is a middleware of the client, see connect.NewConnect
+//
+//	metrics := exporter.NewClientMetrics()
+//	client := connect.NewConnect(url, metrics)
+type ClientMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// NewClientMetrics creates the client metrics, they are reported once registered
+func NewClientMetrics() *ClientMetrics {
+	return &ClientMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "client",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of the requests made to the connect rest API per operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "client",
+			Name:      "errors_total",
+			Help:      "Number of failed requests to the connect rest API per operation and status code, 0 when no response was received.",
+		}, []string{"operation", "code"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *ClientMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.duration.Describe(ch)
+	m.errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *ClientMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.duration.Collect(ch)
+	m.errors.Collect(ch)
+}
+
+// Before implements connect.Middleware
+func (m *ClientMetrics) Before(ctx context.Context, info *connect.RequestInfo) context.Context {
+	return ctx
+}
+
+// After implements connect.Middleware, it records every attempt of a request under its operation
+func (m *ClientMetrics) After(ctx context.Context, info *connect.RequestInfo) {
+	operation := strings.ReplaceAll(info.Operation, " ", "_")
+	m.duration.WithLabelValues(operation).Observe(info.Latency.Seconds())
+	if info.Err != nil || info.StatusCode >= 400 {
+		m.errors.WithLabelValues(operation, strconv.Itoa(info.StatusCode)).Inc()
+	}
+}
@@ -0,0 +1,181 @@
+// Package exporter exposes the state of a kafka connect cluster as prometheus metrics
+package exporter
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/prometheus/client_golang/prometheus"
+	logger "github.com/sirupsen/logrus"
+)
+
+// Namespace prefixes every metric of the exporter
+const Namespace = "kafka_connect"
+
+// States are the states of connectors and tasks reported by the state gauges, one series per state
+var States = []string{"UNASSIGNED", "RUNNING", "PAUSED", "FAILED", "RESTARTING", "STOPPED"}
+
+var (
+	upDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "up"),
+		"Whether the last scrape of the connect rest API succeeded.", nil, nil)
+	scrapeDurationDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "scrape_duration_seconds"),
+		"Time taken by the last scrape of the connect rest API.", nil, nil)
+	connectorsDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "connectors"),
+		"Number of connectors per plugin class.", []string{"class"}, nil)
+	connectorStateDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "connector", "state"),
+		"State of the connector instance, 1 for the current state and 0 for the others.", []string{"connector", "type", "state"}, nil)
+	connectorRestartDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "connector", "last_restart_timestamp_seconds"),
+		"Last time the connector instance was seen RUNNING again after being in another state.", []string{"connector"}, nil)
+	taskStateDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "task", "state"),
+		"State of the task, 1 for the current state and 0 for the others.", []string{"connector", "task", "worker", "state"}, nil)
+	taskRestartDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "task", "last_restart_timestamp_seconds"),
+		"Last time the task was seen RUNNING again after being in another state.", []string{"connector", "task"}, nil)
+	workerTasksDesc = prometheus.NewDesc(prometheus.BuildFQName(Namespace, "worker", "tasks"),
+		"Number of tasks assigned to the worker.", []string{"worker"}, nil)
+)
+
+// stateKey identifies a connector instance (task -1) or one of its tasks across scrapes
+type stateKey struct {
+	connector string
+	task      int
+}
+
+// Collector is a prometheus.Collector reporting the state of the connectors and tasks of a cluster.
+// Every scrape lists the connectors and fetches their status and config.
+// The connect rest API does not expose restarts, so the restart times are the times a connector or task
+// was seen RUNNING after being in another state on the previous scrape
+type Collector struct {
+	client connect.Connect
+
+	mu       sync.Mutex
+	now      func() time.Time
+	states   map[stateKey]string
+	restarts map[stateKey]time.Time
+}
+
+// NewCollector creates a collector scraping the cluster of the client
+func NewCollector(client connect.Connect) *Collector {
+	return &Collector{
+		client:   client,
+		now:      time.Now,
+		states:   map[stateKey]string{},
+		restarts: map[stateKey]time.Time{},
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- upDesc
+	ch <- scrapeDurationDesc
+	ch <- connectorsDesc
+	ch <- connectorStateDesc
+	ch <- connectorRestartDesc
+	ch <- taskStateDesc
+	ch <- taskRestartDesc
+	ch <- workerTasksDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start := time.Now()
+	up := 1.0
+	if err := c.collect(ch); err != nil {
+		logger.WithError(err).Error("Could not scrape connect")
+		up = 0
+	}
+	ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, up)
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(start).Seconds())
+}
+
+func (c *Collector) collect(ch chan<- prometheus.Metric) error {
+	connectors, err := c.client.GetConnectors()
+	if err != nil {
+		return err
+	}
+
+	now := c.now()
+	classes := map[string]int{}
+	workers := map[string]int{}
+	seen := map[stateKey]bool{}
+	for _, name := range connectors.Connectors {
+		status, err := c.client.GetConnectorStatus(name)
+		if connect.IsNotFound(err) {
+			// deleted since it was listed
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		config, err := c.client.GetConnectorConfig(name)
+		if connect.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		class, _ := config.Config["connector.class"].(string)
+		classes[class]++
+
+		key := stateKey{connector: name, task: -1}
+		seen[key] = true
+		state := status.ConnectorStatus["state"]
+		for _, s := range States {
+			ch <- prometheus.MustNewConstMetric(connectorStateDesc, prometheus.GaugeValue, boolValue(s == state), name, status.Type, s)
+		}
+		if restart, ok := c.observe(key, state, now); ok {
+			ch <- prometheus.MustNewConstMetric(connectorRestartDesc, prometheus.GaugeValue, float64(restart.Unix()), name)
+		}
+
+		for _, task := range status.TasksStatus {
+			id := strconv.Itoa(task.ID)
+			key := stateKey{connector: name, task: task.ID}
+			seen[key] = true
+			workers[task.WorkerID]++
+			for _, s := range States {
+				ch <- prometheus.MustNewConstMetric(taskStateDesc, prometheus.GaugeValue, boolValue(s == task.State), name, id, task.WorkerID, s)
+			}
+			if restart, ok := c.observe(key, task.State, now); ok {
+				ch <- prometheus.MustNewConstMetric(taskRestartDesc, prometheus.GaugeValue, float64(restart.Unix()), name, id)
+			}
+		}
+	}
+
+	for class, count := range classes {
+		ch <- prometheus.MustNewConstMetric(connectorsDesc, prometheus.GaugeValue, float64(count), class)
+	}
+	for worker, count := range workers {
+		ch <- prometheus.MustNewConstMetric(workerTasksDesc, prometheus.GaugeValue, float64(count), worker)
+	}
+
+	// forget the deleted connectors and tasks
+	for key := range c.states {
+		if !seen[key] {
+			delete(c.states, key)
+			delete(c.restarts, key)
+		}
+	}
+	return nil
+}
+
+// observe records the state of a connector or task and returns its last restart time, if one was seen
+func (c *Collector) observe(key stateKey, state string, now time.Time) (time.Time, bool) {
+	previous, ok := c.states[key]
+	c.states[key] = state
+	if ok && previous != "RUNNING" && state == "RUNNING" {
+		c.restarts[key] = now
+	}
+	restart, ok := c.restarts[key]
+	return restart, ok
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubConnect serves fixed statuses and configs, the other methods are not implemented
+type stubConnect struct {
+	connect.Connect
+	statuses map[string]*connect.GetConnectorStatusResponse
+	classes  map[string]string
+}
+
+func (s *stubConnect) GetConnectors() (*connect.GetAllConnectorsResponse, error) {
+	response := &connect.GetAllConnectorsResponse{}
+	for _, name := range []string{"a", "b"} {
+		if _, ok := s.statuses[name]; ok {
+			response.Connectors = append(response.Connectors, name)
+		}
+	}
+	return response, nil
+}
+
+func (s *stubConnect) GetConnectorStatus(name string) (*connect.GetConnectorStatusResponse, error) {
+	return s.statuses[name], nil
+}
+
+func (s *stubConnect) GetConnectorConfig(name string) (*connect.GetConnectorConfigResponse, error) {
+	return &connect.GetConnectorConfigResponse{Config: map[string]interface{}{"connector.class": s.classes[name]}}, nil
+}
+
+func status(name, state string, tasks ...connect.TaskStatus) *connect.GetConnectorStatusResponse {
+	return &connect.GetConnectorStatusResponse{
+		Name:            name,
+		Type:            "sink",
+		ConnectorStatus: map[string]string{"state": state, "worker_id": "w1:8083"},
+		TasksStatus:     tasks,
+	}
+}
+
+func TestCollector(t *testing.T) {
+	client := &stubConnect{
+		statuses: map[string]*connect.GetConnectorStatusResponse{
+			"a": status("a", "RUNNING", connect.TaskStatus{ID: 0, State: "FAILED", WorkerID: "w1:8083"}),
+			"b": status("b", "PAUSED", connect.TaskStatus{ID: 0, State: "PAUSED", WorkerID: "w2:8083"}),
+		},
+		classes: map[string]string{"a": "S3SinkConnector", "b": "S3SinkConnector"},
+	}
+	collector := NewCollector(client)
+	collector.now = func() time.Time { return time.Unix(1000, 0) }
+
+	expected := `
+# HELP kafka_connect_connectors Number of connectors per plugin class.
+# TYPE kafka_connect_connectors gauge
+kafka_connect_connectors{class="S3SinkConnector"} 2
+# HELP kafka_connect_worker_tasks Number of tasks assigned to the worker.
+# TYPE kafka_connect_worker_tasks gauge
+kafka_connect_worker_tasks{worker="w1:8083"} 1
+kafka_connect_worker_tasks{worker="w2:8083"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"kafka_connect_connectors", "kafka_connect_worker_tasks", "kafka_connect_task_last_restart_timestamp_seconds"))
+
+	// the failed task is running again
+	client.statuses["a"].TasksStatus[0].State = "RUNNING"
+	expected = `
+# HELP kafka_connect_task_last_restart_timestamp_seconds Last time the task was seen RUNNING again after being in another state.
+# TYPE kafka_connect_task_last_restart_timestamp_seconds gauge
+kafka_connect_task_last_restart_timestamp_seconds{connector="a",task="0"} 1000
+# HELP kafka_connect_task_state State of the task, 1 for the current state and 0 for the others.
+# TYPE kafka_connect_task_state gauge
+kafka_connect_task_state{connector="a",state="FAILED",task="0",worker="w1:8083"} 0
+kafka_connect_task_state{connector="a",state="PAUSED",task="0",worker="w1:8083"} 0
+kafka_connect_task_state{connector="a",state="RESTARTING",task="0",worker="w1:8083"} 0
+kafka_connect_task_state{connector="a",state="RUNNING",task="0",worker="w1:8083"} 1
+kafka_connect_task_state{connector="a",state="STOPPED",task="0",worker="w1:8083"} 0
+kafka_connect_task_state{connector="a",state="UNASSIGNED",task="0",worker="w1:8083"} 0
+kafka_connect_task_state{connector="b",state="FAILED",task="0",worker="w2:8083"} 0
+kafka_connect_task_state{connector="b",state="PAUSED",task="0",worker="w2:8083"} 1
+kafka_connect_task_state{connector="b",state="RESTARTING",task="0",worker="w2:8083"} 0
+kafka_connect_task_state{connector="b",state="RUNNING",task="0",worker="w2:8083"} 0
+kafka_connect_task_state{connector="b",state="STOPPED",task="0",worker="w2:8083"} 0
+kafka_connect_task_state{connector="b",state="UNASSIGNED",task="0",worker="w2:8083"} 0
+# HELP kafka_connect_up Whether the last scrape of the connect rest API succeeded.
+# TYPE kafka_connect_up gauge
+kafka_connect_up 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"kafka_connect_task_last_restart_timestamp_seconds", "kafka_connect_task_state", "kafka_connect_up"))
+}
+
+func TestClientMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_code":409,"message":"rebalance in process"}`))
+			return
+		}
+		w.Write([]byte(`{"name":"db","connector":{"state":"RUNNING"},"tasks":[]}`))
+	}))
+	defer server.Close()
+
+	metrics := NewClientMetrics()
+	client := connect.NewConnect(strings.TrimPrefix(server.URL, "http://"), metrics)
+
+	_, err := client.GetConnectorStatus("db")
+	assert.NoError(t, err)
+	_, err = client.PauseConnector("db")
+	assert.Error(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.errors.WithLabelValues("pause_connector", "409")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.errors))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.duration))
+}
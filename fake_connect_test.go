@@ -0,0 +1,281 @@
+package connect
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// fakeConnect is an in memory implementation of Connect used by the unit tests
+type fakeConnect struct {
+	mu         sync.Mutex
+	connectors map[string]map[string]interface{}
+	states     map[string]string
+	tasks      map[string][]TaskStatus
+	offsets    map[string][]ConnectorOffset
+	calls      []string
+
+	// noExpand simulates a worker that does not support the expanded listing
+	noExpand bool
+}
+
+func newFakeConnect() *fakeConnect {
+	return &fakeConnect{
+		connectors: map[string]map[string]interface{}{},
+		states:     map[string]string{},
+		tasks:      map[string][]TaskStatus{},
+		offsets:    map[string][]ConnectorOffset{},
+	}
+}
+
+// add registers a connector directly, storing its values as strings the way connect does
+func (f *fakeConnect) add(name string, config map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored := map[string]interface{}{"name": name}
+	for key, value := range config {
+		stored[key] = fmt.Sprint(value)
+	}
+	f.connectors[name] = stored
+	if _, ok := f.states[name]; !ok {
+		f.states[name] = "RUNNING"
+	}
+}
+
+// setTasks replaces the task statuses of a connector
+func (f *fakeConnect) setTasks(name string, tasks ...TaskStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tasks[name] = tasks
+}
+
+func (f *fakeConnect) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func (f *fakeConnect) CreateConnectorRequest(req ConnectorRequest) ConnectorRequest {
+	return req
+}
+
+func (f *fakeConnect) GetConnectors() (*GetAllConnectorsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	response := &GetAllConnectorsResponse{}
+	for name := range f.connectors {
+		response.Connectors = append(response.Connectors, name)
+	}
+	sort.Strings(response.Connectors)
+	response.Code = 200
+	return response, nil
+}
+
+func (f *fakeConnect) CreateConnector(req ConnectorRequest) (*ConnectorResponse, error) {
+	f.mu.Lock()
+	if _, ok := f.connectors[req.Name]; ok {
+		f.mu.Unlock()
+		return nil, errors.Errorf("create connector error: connector %v already exists", req.Name)
+	}
+	f.mu.Unlock()
+	if req.InitialState != "" {
+		f.record(fmt.Sprintf("create %v initial_state=%v", req.Name, req.InitialState))
+		f.mu.Lock()
+		f.states[req.Name] = req.InitialState
+		f.mu.Unlock()
+	} else {
+		f.record("create " + req.Name)
+	}
+	f.add(req.Name, req.Config)
+	return f.GetConnector(req.Name)
+}
+
+func (f *fakeConnect) GetConnector(name string) (*ConnectorResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	config, ok := f.connectors[name]
+	if !ok {
+		return nil, errors.Errorf("get connector error: connector %v not found", name)
+	}
+	response := &ConnectorResponse{Name: name, Config: config}
+	response.Code = 200
+	return response, nil
+}
+
+func (f *fakeConnect) GetConnectorConfig(name string) (*GetConnectorConfigResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	config, ok := f.connectors[name]
+	if !ok {
+		return nil, &APIError{Operation: "get connector config", StatusCode: 404, Body: "connector " + name + " not found"}
+	}
+	response := &GetConnectorConfigResponse{Config: config}
+	response.Code = 200
+	return response, nil
+}
+
+func (f *fakeConnect) UpdateConnectorConfig(req ConnectorRequest) (*ConnectorResponse, error) {
+	f.record("update " + req.Name)
+	f.mu.Lock()
+	_, exists := f.connectors[req.Name]
+	f.mu.Unlock()
+	f.add(req.Name, req.Config)
+	response, err := f.GetConnector(req.Name)
+	if err == nil && !exists {
+		response.Code = 201
+	}
+	return response, err
+}
+
+func (f *fakeConnect) GetConnectorStatus(name string) (*GetConnectorStatusResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state, ok := f.states[name]
+	if !ok {
+		return nil, &APIError{Operation: "get connector status", StatusCode: 404, Body: "connector " + name + " not found"}
+	}
+	response := &GetConnectorStatusResponse{
+		Name:            name,
+		ConnectorStatus: map[string]string{"state": state, "worker_id": "worker-1:8083"},
+		TasksStatus:     append([]TaskStatus{}, f.tasks[name]...),
+	}
+	response.Code = 200
+	return response, nil
+}
+
+func (f *fakeConnect) GetConnectorsExpanded() (*GetExpandedConnectorsResponse, error) {
+	if f.noExpand {
+		return nil, ErrExpandNotSupported
+	}
+	names, _ := f.GetConnectors()
+	response := &GetExpandedConnectorsResponse{Connectors: map[string]ExpandedConnector{}}
+	for _, name := range names.Connectors {
+		status, err := f.GetConnectorStatus(name)
+		if err != nil {
+			return nil, err
+		}
+		info, err := f.GetConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		response.Connectors[name] = ExpandedConnector{Status: *status, Info: *info}
+	}
+	response.Code = 200
+	return response, nil
+}
+
+func (f *fakeConnect) setState(name, state string) (*EmptyResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.connectors[name]; !ok {
+		return nil, errors.Errorf("connector %v not found", name)
+	}
+	f.states[name] = state
+	return &EmptyResponse{Code: 202}, nil
+}
+
+func (f *fakeConnect) RestartConnector(name string) (*EmptyResponse, error) {
+	f.record("restart " + name)
+	return f.setState(name, "RUNNING")
+}
+
+// RestartConnectorWithOptions answers like the workers do with the status of the connector, the restarted
+// instances being RESTARTING, while the restart completes at once and the status reads never show RESTARTING
+func (f *fakeConnect) RestartConnectorWithOptions(name string, options RestartOptions) (*GetConnectorStatusResponse, error) {
+	f.record(fmt.Sprintf("restart %v includeTasks=%v onlyFailed=%v", name, options.IncludeTasks, options.OnlyFailed))
+	response, err := f.GetConnectorStatus(name)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !options.OnlyFailed || f.states[name] == "FAILED" {
+		f.states[name] = "RUNNING"
+		response.ConnectorStatus["state"] = "RESTARTING"
+	}
+	for i, task := range f.tasks[name] {
+		if options.IncludeTasks && (!options.OnlyFailed || task.State == "FAILED") {
+			f.tasks[name][i].State = "RUNNING"
+			response.TasksStatus[i].State = "RESTARTING"
+		}
+	}
+	response.Code = 202
+	return response, nil
+}
+
+func (f *fakeConnect) PauseConnector(name string) (*EmptyResponse, error) {
+	f.record("pause " + name)
+	return f.setState(name, "PAUSED")
+}
+
+func (f *fakeConnect) ResumeConnector(name string) (*EmptyResponse, error) {
+	f.record("resume " + name)
+	return f.setState(name, "RUNNING")
+}
+
+func (f *fakeConnect) StopConnector(name string) (*EmptyResponse, error) {
+	f.record("stop " + name)
+	return f.setState(name, "STOPPED")
+}
+
+func (f *fakeConnect) DeleteConnector(name string) (*EmptyResponse, error) {
+	f.record("delete " + name)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.connectors[name]; !ok {
+		return nil, errors.Errorf("delete connector error: connector %v not found", name)
+	}
+	delete(f.connectors, name)
+	delete(f.states, name)
+	delete(f.tasks, name)
+	delete(f.offsets, name)
+	return &EmptyResponse{Code: 204}, nil
+}
+
+func (f *fakeConnect) GetConnectorTasks(name string) (*GetConnectorTasksResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	response := &GetConnectorTasksResponse{Code: 200}
+	for _, task := range f.tasks[name] {
+		response.Tasks = append(response.Tasks, TaskDetails{ID: TaskID{Connector: name, TaskID: task.ID}})
+	}
+	return response, nil
+}
+
+func (f *fakeConnect) GetConnectorTaskStatus(name string, taskId int) (*TaskStatusResponse, error) {
+	return &TaskStatusResponse{Code: 200, Status: TaskStatus{ID: taskId, State: "RUNNING"}}, nil
+}
+
+func (f *fakeConnect) RestartConnectorTask(name string, taskId int) (*EmptyResponse, error) {
+	f.record(fmt.Sprintf("restart %v/%d", name, taskId))
+	return &EmptyResponse{Code: 204}, nil
+}
+
+func (f *fakeConnect) GetConnectorOffsets(name string) (*GetConnectorOffsetsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &GetConnectorOffsetsResponse{Code: 200, Offsets: f.offsets[name]}, nil
+}
+
+func (f *fakeConnect) AlterConnectorOffsets(name string, offsets []ConnectorOffset) (*EmptyResponse, error) {
+	f.record(fmt.Sprintf("alter offsets %v (%d)", name, len(offsets)))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.states[name] != "STOPPED" {
+		return nil, errors.Errorf("alter connector offsets error: connector %v is not stopped", name)
+	}
+	f.offsets[name] = offsets
+	return &EmptyResponse{Code: 200}, nil
+}
+
+func (f *fakeConnect) GetConnectorPlugins() (*ConnectorPluginsResponse, error) {
+	return &ConnectorPluginsResponse{Code: 200}, nil
+}
+
+func (f *fakeConnect) ValidatePluginConfig(pluginName string, req ConnectorRequest) (*ValidateConnectorPluginResponse, error) {
+	return &ValidateConnectorPluginResponse{Code: 200, Name: pluginName}, nil
+}
+
+var _ Connect = (*fakeConnect)(nil)
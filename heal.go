@@ -0,0 +1,395 @@
+package connect
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+)
+
+// DefaultHealInterval is the time between two checks when the interval of the auto healer is not positive
+const DefaultHealInterval = time.Minute
+
+// DefaultHealDenyTraces are the failures the auto healer does not restart by default,
+// because restarting fails again on the same record
+var DefaultHealDenyTraces = []*regexp.Regexp{
+	regexp.MustCompile(`SerializationException`),
+	regexp.MustCompile(`DataException`),
+}
+
+// HealActionType is what the auto healer did about a failure
+type HealActionType string
+
+const (
+	// HealRestarted means the failed connector or task was restarted
+	HealRestarted HealActionType = "restarted"
+	// HealSkipped means the failure was left alone, the reason says why
+	HealSkipped HealActionType = "skipped"
+	// HealRecovered means a connector or task that was restarted is running again
+	HealRecovered HealActionType = "recovered"
+)
+
+// HealAction is a single decision made by the auto healer
+type HealAction struct {
+	Time      time.Time      `json:"time"`
+	Type      HealActionType `json:"type"`
+	Connector string         `json:"connector"`
+	// Task is the id of the restarted task, -1 when the connector instance itself failed
+	Task int `json:"task"`
+	// Attempt is the number of consecutive restarts of the failure, including this one
+	Attempt int    `json:"attempt,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+	Err     error  `json:"-"`
+}
+
+func (a HealAction) String() string {
+	target := a.Connector
+	if a.Task >= 0 {
+		target = fmt.Sprintf("%v/%d", a.Connector, a.Task)
+	}
+	s := fmt.Sprintf("%v %v", a.Type, target)
+	if a.Attempt > 0 {
+		s += fmt.Sprintf(" (attempt %d)", a.Attempt)
+	}
+	if a.Reason != "" {
+		s += ": " + a.Reason
+	}
+	if a.Err != nil {
+		s += fmt.Sprintf(": %v", a.Err)
+	}
+	return s
+}
+
+// HealReport is the history of the actions taken by an auto healer
+type HealReport struct {
+	Actions []HealAction `json:"actions"`
+}
+
+// Count returns the number of actions of the given type
+func (r *HealReport) Count(actionType HealActionType) int {
+	count := 0
+	for _, action := range r.Actions {
+		if action.Type == actionType {
+			count++
+		}
+	}
+	return count
+}
+
+// Connectors returns the sorted names of the connectors with at least one action of the given type
+func (r *HealReport) Connectors(actionType HealActionType) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, action := range r.Actions {
+		if action.Type == actionType && !seen[action.Connector] {
+			seen[action.Connector] = true
+			names = append(names, action.Connector)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Failed returns the restarts that returned an error
+func (r *HealReport) Failed() []HealAction {
+	var failed []HealAction
+	for _, action := range r.Actions {
+		if action.Err != nil {
+			failed = append(failed, action)
+		}
+	}
+	return failed
+}
+
+// healKey identifies a connector instance (task -1) or one of its tasks
+type healKey struct {
+	connector string
+	task      int
+}
+
+// healState tracks the restarts of a single failure
+type healState struct {
+	attempts    int
+	nextAttempt time.Time
+	skipReason  string
+}
+
+// AutoHealer periodically restarts FAILED connectors and tasks.
+// Consecutive restarts of the same failure are spaced with an exponential backoff
+// and the restarts of a connector are capped by a budget over a sliding window
+type AutoHealer struct {
+	client Connect
+
+	// Interval is the time between two checks of the connector statuses, DefaultHealInterval when it is not positive
+	Interval time.Duration
+	// InitialBackoff is the time to wait after the first restart of a failure before restarting it again
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time between two restarts of the same failure
+	MaxBackoff time.Duration
+	// Budget is the maximum number of restarts of a connector and its tasks within BudgetWindow, zero means unlimited
+	Budget       int
+	BudgetWindow time.Duration
+	// AllowTraces restricts restarts to the failures whose trace matches one of the patterns, every failure is allowed when empty
+	AllowTraces []*regexp.Regexp
+	// DenyTraces prevents restarting the failures whose trace matches one of the patterns
+	DenyTraces []*regexp.Regexp
+	// Filter selects the connectors to heal, all connectors are healed when it is nil
+	Filter func(name string) bool
+	// OnError is called when a check fails, the failure is logged when it is nil
+	OnError func(error)
+
+	mu      sync.Mutex
+	started bool
+	// healMu serialises the checks, which own now, failures and restarts
+	healMu   sync.Mutex
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+
+	now      func() time.Time
+	failures map[healKey]*healState
+	restarts map[string][]time.Time
+	report   HealReport
+}
+
+// NewAutoHealer creates an auto healer checking the cluster every interval, DefaultHealInterval when it is not positive
+func NewAutoHealer(client Connect, interval time.Duration) *AutoHealer {
+	if interval <= 0 {
+		interval = DefaultHealInterval
+	}
+	return &AutoHealer{
+		client:         client,
+		Interval:       interval,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     30 * time.Minute,
+		Budget:         10,
+		BudgetWindow:   time.Hour,
+		DenyTraces:     DefaultHealDenyTraces,
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+		now:            time.Now,
+		failures:       map[healKey]*healState{},
+		restarts:       map[string][]time.Time{},
+	}
+}
+
+// Start checks the cluster in the background until Stop is called
+func (h *AutoHealer) Start() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.started {
+		h.started = true
+		go h.run()
+	}
+}
+
+// Stop stops the background checks and waits for the running one to finish
+func (h *AutoHealer) Stop() {
+	h.mu.Lock()
+	started := h.started
+	h.started = true
+	h.mu.Unlock()
+
+	h.stopOnce.Do(func() {
+		close(h.stop)
+		if !started {
+			close(h.done)
+		}
+	})
+	<-h.done
+}
+
+func (h *AutoHealer) run() {
+	defer close(h.done)
+
+	interval := h.Interval
+	if interval <= 0 {
+		interval = DefaultHealInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := h.Heal(); err != nil {
+			if h.OnError != nil {
+				h.OnError(err)
+			} else {
+				logger.WithError(err).Error("Could not heal connectors")
+			}
+		}
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report returns a copy of every action taken since the auto healer was created
+func (h *AutoHealer) Report() HealReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return HealReport{Actions: append([]HealAction{}, h.report.Actions...)}
+}
+
+// Heal checks the status of every connector once and restarts the failures that are due.
+// It is called by the background loop started with Start, and can be called directly instead or concurrently,
+// the checks then run one after the other
+func (h *AutoHealer) Heal() ([]HealAction, error) {
+	h.healMu.Lock()
+	defer h.healMu.Unlock()
+
+	connectors, err := h.client.GetConnectors()
+	if err != nil {
+		return nil, errors.Wrap(err, "heal error: could not list connectors")
+	}
+
+	var actions []HealAction
+	seen := map[healKey]bool{}
+	for _, name := range connectors.Connectors {
+		if h.Filter != nil && !h.Filter(name) {
+			continue
+		}
+		status, err := h.client.GetConnectorStatus(name)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return actions, errors.Wrapf(err, "heal error: could not get status of %v", name)
+		}
+
+		connectorKey := healKey{connector: name, task: -1}
+		seen[connectorKey] = true
+		// restarting the connector instance does not restart its tasks, the failed ones are healed below
+		if status.ConnectorStatus["state"] == "FAILED" {
+			actions = h.appendAction(actions, h.heal(connectorKey, status.ConnectorStatus["trace"]))
+		} else {
+			actions = h.appendAction(actions, h.recovered(connectorKey))
+		}
+
+		for _, task := range status.TasksStatus {
+			key := healKey{connector: name, task: task.ID}
+			seen[key] = true
+			if task.State == "FAILED" {
+				actions = h.appendAction(actions, h.heal(key, task.Trace))
+			} else {
+				actions = h.appendAction(actions, h.recovered(key))
+			}
+		}
+	}
+
+	// forget the failures of deleted connectors and tasks
+	for key := range h.failures {
+		if !seen[key] {
+			delete(h.failures, key)
+		}
+	}
+
+	h.mu.Lock()
+	h.report.Actions = append(h.report.Actions, actions...)
+	h.mu.Unlock()
+	return actions, nil
+}
+
+func (h *AutoHealer) appendAction(actions []HealAction, action *HealAction) []HealAction {
+	if action == nil {
+		return actions
+	}
+	if action.Err != nil {
+		logger.WithError(action.Err).Errorf("Could not restart %v", action)
+	} else {
+		logger.Info(action.String())
+	}
+	return append(actions, *action)
+}
+
+// recovered clears the failure of a connector or task that is no longer FAILED
+func (h *AutoHealer) recovered(key healKey) *HealAction {
+	state, ok := h.failures[key]
+	if !ok {
+		return nil
+	}
+	delete(h.failures, key)
+	if state.attempts == 0 {
+		return nil
+	}
+	return &HealAction{Time: h.now(), Type: HealRecovered, Connector: key.connector, Task: key.task, Attempt: state.attempts}
+}
+
+// heal restarts a failure if it is allowed and due, returns nil when there is nothing new to report
+func (h *AutoHealer) heal(key healKey, trace string) *HealAction {
+	now := h.now()
+	state, ok := h.failures[key]
+	if !ok {
+		state = &healState{}
+		h.failures[key] = state
+	}
+
+	skip := func(reason string) *HealAction {
+		// a failure that stays skipped for the same reason is only reported once
+		if state.skipReason == reason {
+			return nil
+		}
+		state.skipReason = reason
+		return &HealAction{Time: now, Type: HealSkipped, Connector: key.connector, Task: key.task, Reason: reason, Trace: trace}
+	}
+
+	if pattern := matchTrace(h.DenyTraces, trace); pattern != nil {
+		return skip(fmt.Sprintf("trace matches denied pattern %q", pattern))
+	}
+	if len(h.AllowTraces) > 0 && matchTrace(h.AllowTraces, trace) == nil {
+		return skip("trace does not match any allowed pattern")
+	}
+	if now.Before(state.nextAttempt) {
+		return nil
+	}
+	if h.Budget > 0 {
+		recent := h.restarts[key.connector][:0]
+		for _, restart := range h.restarts[key.connector] {
+			if now.Sub(restart) < h.BudgetWindow {
+				recent = append(recent, restart)
+			}
+		}
+		h.restarts[key.connector] = recent
+		if len(recent) >= h.Budget {
+			return skip(fmt.Sprintf("restart budget of %d per %v exhausted", h.Budget, h.BudgetWindow))
+		}
+	}
+
+	var err error
+	if key.task < 0 {
+		_, err = h.client.RestartConnector(key.connector)
+	} else {
+		_, err = h.client.RestartConnectorTask(key.connector, key.task)
+	}
+	state.skipReason = ""
+	state.attempts++
+	state.nextAttempt = now.Add(h.backoff(state.attempts))
+	h.restarts[key.connector] = append(h.restarts[key.connector], now)
+	return &HealAction{Time: now, Type: HealRestarted, Connector: key.connector, Task: key.task, Attempt: state.attempts, Trace: trace, Err: err}
+}
+
+// backoff returns the time to wait after the given number of consecutive restarts
+func (h *AutoHealer) backoff(attempts int) time.Duration {
+	backoff := h.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if h.MaxBackoff > 0 && backoff >= h.MaxBackoff {
+			return h.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+func matchTrace(patterns []*regexp.Regexp, trace string) *regexp.Regexp {
+	for _, pattern := range patterns {
+		if pattern.MatchString(trace) {
+			return pattern
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package connect
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAutoHealer(fake *fakeConnect) (*AutoHealer, *time.Time) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	healer := NewAutoHealer(fake, time.Minute)
+	healer.InitialBackoff = time.Minute
+	healer.MaxBackoff = 4 * time.Minute
+	healer.now = func() time.Time { return now }
+	return healer, &now
+}
+
+func TestAutoHealer_Backoff(t *testing.T) {
+	fake := newFakeConnect()
+	fake.add("a", nil)
+	fake.setTasks("a", TaskStatus{ID: 0, State: "FAILED", Trace: "java.net.ConnectException"}, TaskStatus{ID: 1, State: "RUNNING"})
+	healer, now := newTestAutoHealer(fake)
+
+	// restarts are spaced 1, 2, 4 and 4 minutes apart
+	var restarts []time.Duration
+	start := *now
+	for i := 0; i < 12; i++ {
+		actions, err := healer.Heal()
+		assert.NoError(t, err)
+		for _, action := range actions {
+			assert.Equal(t, HealRestarted, action.Type)
+			assert.Equal(t, 0, action.Task)
+			restarts = append(restarts, now.Sub(start))
+		}
+		*now = now.Add(time.Minute)
+	}
+	assert.Equal(t, []time.Duration{0, time.Minute, 3 * time.Minute, 7 * time.Minute, 11 * time.Minute}, restarts)
+	assert.Equal(t, 5, len(fake.calls))
+	assert.Equal(t, "restart a/0", fake.calls[0])
+
+	fake.setTasks("a", TaskStatus{ID: 0, State: "RUNNING"}, TaskStatus{ID: 1, State: "RUNNING"})
+	actions, err := healer.Heal()
+	assert.NoError(t, err)
+	assert.Equal(t, []HealAction{{Time: *now, Type: HealRecovered, Connector: "a", Task: 0, Attempt: 5}}, actions)
+
+	report := healer.Report()
+	assert.Equal(t, 5, report.Count(HealRestarted))
+	assert.Equal(t, []string{"a"}, report.Connectors(HealRecovered))
+}
+
+func TestAutoHealer_Budget(t *testing.T) {
+	fake := newFakeConnect()
+	fake.add("a", nil)
+	fake.setTasks("a", TaskStatus{ID: 0, State: "FAILED"}, TaskStatus{ID: 1, State: "FAILED"})
+	healer, now := newTestAutoHealer(fake)
+	healer.Budget = 3
+	healer.BudgetWindow = 10 * time.Minute
+
+	actions, _ := healer.Heal()
+	assert.Equal(t, 2, len(actions))
+	*now = now.Add(time.Minute)
+	actions, _ = healer.Heal()
+	assert.Equal(t, HealRestarted, actions[0].Type)
+	assert.Equal(t, HealSkipped, actions[1].Type)
+	assert.Equal(t, "restart budget of 3 per 10m0s exhausted", actions[1].Reason)
+
+	// the skip is reported once, task 0 is skipped once its backoff expires
+	*now = now.Add(time.Minute)
+	actions, _ = healer.Heal()
+	assert.Empty(t, actions)
+	*now = now.Add(time.Minute)
+	actions, _ = healer.Heal()
+	assert.Equal(t, 1, len(actions))
+	assert.Equal(t, "skipped a/0: restart budget of 3 per 10m0s exhausted", actions[0].String())
+
+	// the budget frees up once the first restarts leave the window
+	*now = now.Add(8 * time.Minute)
+	actions, _ = healer.Heal()
+	assert.Equal(t, 2, len(actions))
+	assert.Equal(t, HealRestarted, actions[0].Type)
+	assert.Equal(t, HealRestarted, actions[1].Type)
+}
+
+func TestAutoHealer_Traces(t *testing.T) {
+	fake := newFakeConnect()
+	fake.add("a", nil)
+	fake.setTasks("a",
+		TaskStatus{ID: 0, State: "FAILED", Trace: "org.apache.kafka.common.errors.SerializationException: Unknown magic byte!"},
+		TaskStatus{ID: 1, State: "FAILED", Trace: "java.sql.SQLException: connection refused"},
+		TaskStatus{ID: 2, State: "FAILED", Trace: "java.lang.NullPointerException"})
+	healer, _ := newTestAutoHealer(fake)
+	healer.AllowTraces = []*regexp.Regexp{regexp.MustCompile(`SQLException|ConnectException`)}
+
+	actions, err := healer.Heal()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(actions))
+	assert.Equal(t, `skipped a/0: trace matches denied pattern "SerializationException"`, actions[0].String())
+	assert.Equal(t, "restarted a/1 (attempt 1)", actions[1].String())
+	assert.Equal(t, "skipped a/2: trace does not match any allowed pattern", actions[2].String())
+	assert.Equal(t, []string{"restart a/1"}, fake.calls)
+}
+
+func TestAutoHealer_FailedConnector(t *testing.T) {
+	fake := newFakeConnect()
+	fake.add("a", nil)
+	fake.add("b", nil)
+	fake.setState("a", "FAILED")
+	fake.setTasks("a", TaskStatus{ID: 0, State: "FAILED"}, TaskStatus{ID: 1, State: "RUNNING"}, TaskStatus{ID: 2, State: "FAILED"})
+	fake.setTasks("b", TaskStatus{ID: 0, State: "FAILED"})
+	healer, now := newTestAutoHealer(fake)
+	healer.Filter = func(name string) bool { return name == "a" }
+
+	// the connector restart does not restart the tasks, the failed ones are restarted too
+	actions, err := healer.Heal()
+	assert.NoError(t, err)
+	assert.Equal(t, []HealAction{
+		{Time: *now, Type: HealRestarted, Connector: "a", Task: -1, Attempt: 1},
+		{Time: *now, Type: HealRestarted, Connector: "a", Task: 0, Attempt: 1},
+		{Time: *now, Type: HealRestarted, Connector: "a", Task: 2, Attempt: 1},
+	}, actions)
+	assert.Equal(t, []string{"restart a", "restart a/0", "restart a/2"}, fake.calls)
+
+	// the task that is still failing stays tracked while the connector recovers
+	fake.setTasks("a", TaskStatus{ID: 0, State: "RUNNING"}, TaskStatus{ID: 1, State: "RUNNING"}, TaskStatus{ID: 2, State: "FAILED"})
+	*now = now.Add(time.Minute)
+	actions, err = healer.Heal()
+	assert.NoError(t, err)
+	assert.Equal(t, []HealAction{
+		{Time: *now, Type: HealRecovered, Connector: "a", Task: -1, Attempt: 1},
+		{Time: *now, Type: HealRecovered, Connector: "a", Task: 0, Attempt: 1},
+		{Time: *now, Type: HealRestarted, Connector: "a", Task: 2, Attempt: 2},
+	}, actions)
+}
+
+func TestAutoHealer_Start(t *testing.T) {
+	fake := newFakeConnect()
+	fake.add("a", nil)
+	fake.setTasks("a", TaskStatus{ID: 0, State: "FAILED", Trace: "java.net.ConnectException"})
+	healer := NewAutoHealer(fake, 0)
+	assert.Equal(t, DefaultHealInterval, healer.Interval)
+
+	// the direct checks run alongside the background one
+	healer.Interval = -1
+	healer.Start()
+	for i := 0; i < 5; i++ {
+		_, err := healer.Heal()
+		assert.NoError(t, err)
+	}
+	healer.Stop()
+	report := healer.Report()
+	assert.Equal(t, 1, report.Count(HealRestarted))
+}
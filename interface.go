@@ -4,14 +4,17 @@ type Connect interface {
 	// connector
 	CreateConnectorRequest(ConnectorRequest) ConnectorRequest
 	GetConnectors() (*GetAllConnectorsResponse, error)
+	GetConnectorsExpanded() (*GetExpandedConnectorsResponse, error)
 	CreateConnector(request ConnectorRequest) (*ConnectorResponse, error)
 	GetConnector(connectorName string) (*ConnectorResponse, error)
 	GetConnectorConfig(connectorName string) (*GetConnectorConfigResponse, error)
 	UpdateConnectorConfig(request ConnectorRequest) (*ConnectorResponse, error)
 	GetConnectorStatus(connectorName string) (*GetConnectorStatusResponse, error)
 	RestartConnector(connectorName string) (*EmptyResponse, error)
+	RestartConnectorWithOptions(connectorName string, options RestartOptions) (*GetConnectorStatusResponse, error)
 	PauseConnector(connectorName string) (*EmptyResponse, error)
 	ResumeConnector(connectorName string) (*EmptyResponse, error)
+	StopConnector(connectorName string) (*EmptyResponse, error)
 	DeleteConnector(connectorName string) (*EmptyResponse, error)
 
 	// Tasks
@@ -19,6 +22,10 @@ type Connect interface {
 	GetConnectorTaskStatus(connectorName string, taskId int) (*TaskStatusResponse, error)
 	RestartConnectorTask(connectorName string, taskId int) (*EmptyResponse, error)
 
+	// offsets
+	GetConnectorOffsets(connectorName string) (*GetConnectorOffsetsResponse, error)
+	AlterConnectorOffsets(connectorName string, offsets []ConnectorOffset) (*EmptyResponse, error)
+
 	// plugins
 	GetConnectorPlugins() (*ConnectorPluginsResponse, error)
 	ValidatePluginConfig(pluginName string, request ConnectorRequest) (*ValidateConnectorPluginResponse, error)
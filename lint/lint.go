@@ -0,0 +1,156 @@
+// Package lint checks connector configs offline, without a worker, against a set of rules
+//
+//	linter := lint.NewLinter()
+//	linter.SetSeverity("naming-convention", lint.SeverityError)
+//	findings := linter.LintFile("connectors/orders.yaml", request)
+//	err := linter.WriteSARIF(os.Stdout, findings)
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+)
+
+// Severity is how serious a finding is, the levels map to the SARIF levels
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+	// SeverityOff disables a rule
+	SeverityOff Severity = "off"
+)
+
+// Problem is what a rule reports on a connector config
+type Problem struct {
+	// Key is the config key at fault, empty when the problem is about the whole connector
+	Key     string
+	Message string
+}
+
+// Rule checks a connector config, rules are added to a Linter
+type Rule struct {
+	ID          string
+	Description string
+	// Severity is the default severity of the findings, it can be changed with Linter.SetSeverity
+	Severity Severity
+	Check    func(request connect.ConnectorRequest) []Problem
+}
+
+// Finding is a problem reported by a rule on a connector
+type Finding struct {
+	Rule      string   `json:"rule"`
+	Severity  Severity `json:"severity"`
+	File      string   `json:"file,omitempty"`
+	Connector string   `json:"connector"`
+	Key       string   `json:"key,omitempty"`
+	Message   string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	location := f.Connector
+	if f.File != "" {
+		location = f.File + ": " + location
+	}
+	if f.Key != "" {
+		location += ": " + f.Key
+	}
+	return fmt.Sprintf("%v: %v: %v [%v]", location, f.Severity, f.Message, f.Rule)
+}
+
+// Linter runs rules over connector configs
+type Linter struct {
+	rules      []Rule
+	severities map[string]Severity
+}
+
+// NewLinter creates a linter with the DefaultRules
+func NewLinter() *Linter {
+	return NewLinterWithRules(DefaultRules()...)
+}
+
+// NewLinterWithRules creates a linter with the given rules only
+func NewLinterWithRules(rules ...Rule) *Linter {
+	l := &Linter{severities: map[string]Severity{}}
+	l.Add(rules...)
+	return l
+}
+
+// Add adds rules, replacing the rules with the same ID
+func (l *Linter) Add(rules ...Rule) {
+	for _, rule := range rules {
+		replaced := false
+		for i := range l.rules {
+			if l.rules[i].ID == rule.ID {
+				l.rules[i], replaced = rule, true
+			}
+		}
+		if !replaced {
+			l.rules = append(l.rules, rule)
+		}
+	}
+}
+
+// SetSeverity changes the severity of the findings of a rule, SeverityOff disables it
+func (l *Linter) SetSeverity(id string, severity Severity) {
+	l.severities[id] = severity
+}
+
+// Rules returns the enabled rules with their effective severity
+func (l *Linter) Rules() []Rule {
+	var rules []Rule
+	for _, rule := range l.rules {
+		if severity, ok := l.severities[rule.ID]; ok {
+			rule.Severity = severity
+		}
+		if rule.Severity != SeverityOff {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Lint returns the findings of the enabled rules on the connector, sorted by key
+func (l *Linter) Lint(request connect.ConnectorRequest) []Finding {
+	return l.LintFile("", request)
+}
+
+// LintFile returns the findings of the enabled rules on the connector read from the file
+func (l *Linter) LintFile(file string, request connect.ConnectorRequest) []Finding {
+	var findings []Finding
+	for _, rule := range l.Rules() {
+		for _, problem := range rule.Check(request) {
+			findings = append(findings, Finding{
+				Rule:      rule.ID,
+				Severity:  rule.Severity,
+				File:      file,
+				Connector: request.Name,
+				Key:       problem.Key,
+				Message:   problem.Message,
+			})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Key < findings[j].Key
+	})
+	return findings
+}
+
+// Count returns the number of findings with the severity
+func Count(findings []Finding, severity Severity) int {
+	count := 0
+	for _, finding := range findings {
+		if finding.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// configValue returns the config value as the string connect stores
+func configValue(config map[string]interface{}, key string) string {
+	return connect.NormalizeConfigValue(config[key])
+}
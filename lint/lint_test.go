@@ -0,0 +1,105 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var replicator = connect.ConnectorRequest{
+	Name: "Example_Connector",
+	Config: map[string]interface{}{
+		"connector.class":             "io.confluent.connect.replicator.ReplicatorSourceConnector",
+		"topic.whitelist":             "users",
+		"key.converter":               "io.confluent.connect.replicator.util.ByteArrayConverter",
+		"value.converter":             "com.example.MyConverter",
+		"src.kafka.ssl.key.password":  "changeit",
+		"dest.kafka.sasl.jaas.config": "${file:/secrets/jaas.properties:config}",
+		"tasks.max":                   "two",
+	},
+}
+
+func TestLinter_Lint(t *testing.T) {
+	findings := NewLinter().LintFile("replicator.json", replicator)
+	var lines []string
+	for _, finding := range findings {
+		lines = append(lines, finding.String())
+	}
+	assert.Equal(t, []string{
+		`replicator.json: Example_Connector: warning: name "Example_Connector" does not match ^[a-z0-9]+([._-][a-z0-9]+)*$ [naming-convention]`,
+		`replicator.json: Example_Connector: src.kafka.ssl.key.password: error: secret in plain text, use a ConfigProvider reference [plaintext-secret]`,
+		`replicator.json: Example_Connector: tasks.max: error: tasks.max "two" is not a positive integer [tasks-max-not-numeric]`,
+		`replicator.json: Example_Connector: topic.whitelist: warning: topic.whitelist is deprecated, use topic.include [deprecated-key]`,
+		`replicator.json: Example_Connector: value.converter: warning: unknown converter class com.example.MyConverter [unknown-converter-class]`,
+	}, lines)
+}
+
+func TestLinter_Rules(t *testing.T) {
+	linter := NewLinterWithRules(MissingConnectorClass, NamingConvention(DefaultNamePattern))
+	linter.SetSeverity("missing-connector-class", SeverityOff)
+	linter.Add(NamingConvention(regexp.MustCompile(`^team-`)))
+	linter.Add(Rule{ID: "no-tasks-max", Severity: SeverityNote, Check: func(request connect.ConnectorRequest) []Problem {
+		if _, ok := request.Config["tasks.max"]; !ok {
+			return []Problem{{Key: "tasks.max", Message: "tasks.max is not set"}}
+		}
+		return nil
+	}})
+
+	findings := linter.Lint(connect.ConnectorRequest{Name: "orders", Config: map[string]interface{}{}})
+	assert.Equal(t, []Finding{
+		{Rule: "naming-convention", Severity: SeverityWarning, Connector: "orders", Message: `name "orders" does not match ^team-`},
+		{Rule: "no-tasks-max", Severity: SeverityNote, Connector: "orders", Key: "tasks.max", Message: "tasks.max is not set"},
+	}, findings)
+	assert.Equal(t, 1, Count(findings, SeverityNote))
+	assert.Equal(t, 0, Count(findings, SeverityError))
+}
+
+func TestWriteSARIF(t *testing.T) {
+	linter := NewLinter()
+	var buf bytes.Buffer
+	assert.NoError(t, linter.WriteSARIF(&buf, linter.LintFile("replicator.json", replicator)))
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+					LogicalLocations []struct {
+						FullyQualifiedName string `json:"fullyQualifiedName"`
+					} `json:"logicalLocations"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Len(t, log.Runs[0].Tool.Driver.Rules, 6)
+	assert.Len(t, log.Runs[0].Results, 5)
+	result := log.Runs[0].Results[1]
+	assert.Equal(t, "plaintext-secret", result.RuleID)
+	assert.Equal(t, "error", result.Level)
+	assert.Equal(t, "replicator.json", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "Example_Connector.src.kafka.ssl.key.password", result.Locations[0].LogicalLocations[0].FullyQualifiedName)
+
+	buf.Reset()
+	assert.NoError(t, WriteJSON(&buf, nil))
+	assert.Equal(t, "[]\n", buf.String())
+}
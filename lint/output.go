@@ -0,0 +1,116 @@
+package lint
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// sarif 2.1.0 log, only the properties written by WriteSARIF
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level Severity `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     Severity        `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	} `json:"artifactLocation"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteJSON writes the findings as a JSON array
+func WriteJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(findings)
+}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log, with the enabled rules of the linter as the rules of the tool
+func (l *Linter) WriteSARIF(w io.Writer, findings []Finding) error {
+	driver := sarifDriver{
+		Name:           "kconnect-lint",
+		InformationURI: "https://github.com/kevinsamoei/kafka-connect-go",
+		Rules:          []sarifRule{},
+	}
+	for _, rule := range l.Rules() {
+		r := sarifRule{ID: rule.ID, ShortDescription: sarifMessage{Text: rule.Description}}
+		r.DefaultConfiguration.Level = rule.Severity
+		driver.Rules = append(driver.Rules, r)
+	}
+
+	results := []sarifResult{}
+	for _, finding := range findings {
+		location := sarifLocation{}
+		if finding.File != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{}
+			location.PhysicalLocation.ArtifactLocation.URI = finding.File
+		}
+		logical := sarifLogicalLocation{Name: finding.Connector, FullyQualifiedName: finding.Connector, Kind: "object"}
+		if finding.Key != "" {
+			logical = sarifLogicalLocation{Name: finding.Key, FullyQualifiedName: finding.Connector + "." + finding.Key, Kind: "member"}
+		}
+		location.LogicalLocations = []sarifLogicalLocation{logical}
+		results = append(results, sarifResult{
+			RuleID:    finding.Rule,
+			Level:     finding.Severity,
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
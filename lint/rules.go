@@ -0,0 +1,193 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/kevinsamoei/kafka-connect-go/connectors/converters"
+)
+
+// KnownConverters are the converter classes accepted by UnknownConverterClass
+var KnownConverters = []string{
+	converters.StringClass,
+	converters.ByteArrayClass,
+	converters.JSONClass,
+	converters.AvroClass,
+	converters.ProtobufClass,
+	converters.JSONSchemaClass,
+	"org.apache.kafka.connect.converters.BooleanConverter",
+	"org.apache.kafka.connect.converters.DoubleConverter",
+	"org.apache.kafka.connect.converters.FloatConverter",
+	"org.apache.kafka.connect.converters.IntegerConverter",
+	"org.apache.kafka.connect.converters.LongConverter",
+	"org.apache.kafka.connect.converters.ShortConverter",
+	"org.apache.kafka.connect.storage.SimpleHeaderConverter",
+	"io.confluent.connect.replicator.util.ByteArrayConverter",
+}
+
+// Deprecation is a config key renamed by the connectors whose class starts with ClassPrefix
+type Deprecation struct {
+	ClassPrefix string
+	Key         string
+	Replacement string
+}
+
+// DeprecatedKeys are the deprecations reported by DeprecatedKey
+var DeprecatedKeys = []Deprecation{
+	{"io.confluent.connect.replicator.", "topic.whitelist", "topic.include"},
+	{"io.confluent.connect.replicator.", "topic.blacklist", "topic.exclude"},
+	{"io.debezium.", "database.server.name", "topic.prefix"},
+	{"io.debezium.", "database.history.kafka.bootstrap.servers", "schema.history.internal.kafka.bootstrap.servers"},
+	{"io.debezium.", "database.history.kafka.topic", "schema.history.internal.kafka.topic"},
+	{"io.debezium.", "database.whitelist", "database.include.list"},
+	{"io.debezium.", "database.blacklist", "database.exclude.list"},
+	{"io.debezium.", "schema.whitelist", "schema.include.list"},
+	{"io.debezium.", "schema.blacklist", "schema.exclude.list"},
+	{"io.debezium.", "table.whitelist", "table.include.list"},
+	{"io.debezium.", "table.blacklist", "table.exclude.list"},
+	{"io.debezium.", "column.whitelist", "column.include.list"},
+	{"io.debezium.", "column.blacklist", "column.exclude.list"},
+}
+
+// DefaultNamePattern is the connector naming convention of NamingConvention: lower case words
+// separated by dashes, dots or underscores
+var DefaultNamePattern = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`)
+
+// referencePattern matches a value that is a ConfigProvider reference such as ${file:/secrets/db.properties:password}
+var referencePattern = regexp.MustCompile(`\$\{[A-Za-z0-9_-]+:[^}]+\}`)
+
+// DefaultRules are the rules of NewLinter
+func DefaultRules() []Rule {
+	return []Rule{
+		MissingConnectorClass,
+		TasksMaxNotNumeric,
+		UnknownConverterClass,
+		PlaintextSecret,
+		DeprecatedKey,
+		NamingConvention(DefaultNamePattern),
+	}
+}
+
+// MissingConnectorClass reports configs without a connector.class
+var MissingConnectorClass = Rule{
+	ID:          "missing-connector-class",
+	Description: "connector.class is required",
+	Severity:    SeverityError,
+	Check: func(request connect.ConnectorRequest) []Problem {
+		if configValue(request.Config, "connector.class") == "" {
+			return []Problem{{Key: "connector.class", Message: "connector.class is not set"}}
+		}
+		return nil
+	},
+}
+
+// TasksMaxNotNumeric reports a tasks.max that is not a positive integer
+var TasksMaxNotNumeric = Rule{
+	ID:          "tasks-max-not-numeric",
+	Description: "tasks.max must be a positive integer",
+	Severity:    SeverityError,
+	Check: func(request connect.ConnectorRequest) []Problem {
+		if _, ok := request.Config["tasks.max"]; !ok {
+			return nil
+		}
+		value := configValue(request.Config, "tasks.max")
+		if n, err := strconv.Atoi(value); err != nil || n < 1 {
+			return []Problem{{Key: "tasks.max", Message: fmt.Sprintf("tasks.max %q is not a positive integer", value)}}
+		}
+		return nil
+	},
+}
+
+// UnknownConverterClass reports key, value and header converters that are not in KnownConverters
+var UnknownConverterClass = Rule{
+	ID:          "unknown-converter-class",
+	Description: "converters should be one of the known converter classes",
+	Severity:    SeverityWarning,
+	Check: func(request connect.ConnectorRequest) []Problem {
+		var problems []Problem
+		for _, key := range []string{"key.converter", "value.converter", "header.converter"} {
+			class := configValue(request.Config, key)
+			if class == "" || contains(KnownConverters, class) {
+				continue
+			}
+			problems = append(problems, Problem{Key: key, Message: fmt.Sprintf("unknown converter class %v", class)})
+		}
+		return problems
+	},
+}
+
+// PlaintextSecret reports sensitive values, as decided by connect.DefaultRedactor, that are not ConfigProvider references
+var PlaintextSecret = Rule{
+	ID:          "plaintext-secret",
+	Description: "secrets must be ConfigProvider references such as ${file:path:key} instead of plain text",
+	Severity:    SeverityError,
+	Check: func(request connect.ConnectorRequest) []Problem {
+		var problems []Problem
+		for _, key := range sortedKeys(request.Config) {
+			value := configValue(request.Config, key)
+			if value == "" || !connect.DefaultRedactor.IsSensitive(key) || referencePattern.MatchString(value) {
+				continue
+			}
+			problems = append(problems, Problem{Key: key, Message: "secret in plain text, use a ConfigProvider reference"})
+		}
+		return problems
+	},
+}
+
+// DeprecatedKey reports the keys in DeprecatedKeys
+var DeprecatedKey = Rule{
+	ID:          "deprecated-key",
+	Description: "deprecated config keys should be replaced",
+	Severity:    SeverityWarning,
+	Check: func(request connect.ConnectorRequest) []Problem {
+		class := configValue(request.Config, "connector.class")
+		var problems []Problem
+		for _, deprecation := range DeprecatedKeys {
+			if _, ok := request.Config[deprecation.Key]; !ok || !strings.HasPrefix(class, deprecation.ClassPrefix) {
+				continue
+			}
+			problems = append(problems, Problem{
+				Key:     deprecation.Key,
+				Message: fmt.Sprintf("%v is deprecated, use %v", deprecation.Key, deprecation.Replacement),
+			})
+		}
+		return problems
+	},
+}
+
+// NamingConvention reports connector names that do not match the pattern
+func NamingConvention(pattern *regexp.Regexp) Rule {
+	return Rule{
+		ID:          "naming-convention",
+		Description: "connector names must match " + pattern.String(),
+		Severity:    SeverityWarning,
+		Check: func(request connect.ConnectorRequest) []Problem {
+			if pattern.MatchString(request.Name) {
+				return nil
+			}
+			return []Problem{{Message: fmt.Sprintf("name %q does not match %v", request.Name, pattern)}}
+		},
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(config map[string]interface{}) []string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
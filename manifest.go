@@ -0,0 +1,360 @@
+package connect
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestAPIVersion is the version of the manifest format understood by this library
+const ManifestAPIVersion = "kafka-connect-go/v1"
+
+// ManifestKind is the kind of a connector manifest
+const ManifestKind = "Connector"
+
+// ConnectorState is the desired state of a connector
+type ConnectorState string
+
+const (
+	// StateRunning is a connector that processes records
+	StateRunning ConnectorState = "running"
+	// StatePaused is a connector whose tasks are paused but still assigned to workers
+	StatePaused ConnectorState = "paused"
+	// StateStopped is a connector that has no running tasks
+	StateStopped ConnectorState = "stopped"
+)
+
+// Manifest is a versioned connector definition kept in YAML or JSON files
+//
+//	apiVersion: kafka-connect-go/v1
+//	kind: Connector
+//	metadata:
+//	  name: users-replicator
+//	  labels:
+//	    team: data
+//	  cluster: dc2
+//	  state: running
+//	config:
+//	  connector.class: io.confluent.connect.replicator.ReplicatorSourceConnector
+//	  tasks.max: 1
+//	offsets:
+//	  - partition: {topic: users, partition: 0}
+//	    offset: {offset: 42}
+type Manifest struct {
+	APIVersion string                 `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string                 `json:"kind" yaml:"kind"`
+	Metadata   ManifestMetadata       `json:"metadata" yaml:"metadata"`
+	Config     map[string]interface{} `json:"config" yaml:"config"`
+	Offsets    []ConnectorOffset      `json:"offsets,omitempty" yaml:"offsets,omitempty"`
+
+	// Source is the file and line the manifest was loaded from
+	Source string `json:"-" yaml:"-"`
+}
+
+// ManifestMetadata identifies a connector and where and how it runs
+type ManifestMetadata struct {
+	Name   string            `json:"name" yaml:"name"`
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Cluster is the name of the connect cluster the connector belongs to, empty for any cluster
+	Cluster string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	// State is the desired state of the connector, running when empty
+	State ConnectorState `json:"state,omitempty" yaml:"state,omitempty"`
+}
+
+// ConnectorRequest returns the request used to create or update the connector. A paused or stopped
+// connector is created in that state, the initial state is not sent for running connectors so that
+// workers older than kafka 3.5 accept the request
+func (m *Manifest) ConnectorRequest() ConnectorRequest {
+	req := ConnectorRequest{
+		Name:   m.Metadata.Name,
+		Config: m.Config,
+	}
+	if state := m.DesiredState(); state != StateRunning {
+		req.InitialState = strings.ToUpper(string(state))
+	}
+	return req
+}
+
+// DesiredState returns the desired state of the connector, defaulting to running
+func (m *Manifest) DesiredState() ConnectorState {
+	if m.Metadata.State == "" {
+		return StateRunning
+	}
+	return m.Metadata.State
+}
+
+// ManifestError is a problem found while loading a manifest, with its location
+type ManifestError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ManifestError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%v: %v", e.File, e.Message)
+	}
+	return fmt.Sprintf("%v:%d:%d: %v", e.File, e.Line, e.Column, e.Message)
+}
+
+// ManifestErrors are all the problems found while loading manifests
+type ManifestErrors []*ManifestError
+
+func (e ManifestErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// LoadManifests loads the manifests from a file or from all the .yaml, .yml and .json files in a directory tree.
+// Every problem found is reported as a ManifestError in the returned ManifestErrors
+func LoadManifests(path string) ([]*Manifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load manifests")
+	}
+
+	var files []string
+	if info.IsDir() {
+		err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(filepath.Ext(file)) {
+			case ".yaml", ".yml", ".json":
+				if !info.IsDir() {
+					files = append(files, file)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load manifests")
+		}
+		sort.Strings(files)
+	} else {
+		files = []string{path}
+	}
+
+	var manifests []*Manifest
+	var errs ManifestErrors
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load manifests")
+		}
+		loaded, err := ParseManifests(file, data)
+		if err != nil {
+			if fileErrs, ok := err.(ManifestErrors); ok {
+				errs = append(errs, fileErrs...)
+				continue
+			}
+			return nil, err
+		}
+		manifests = append(manifests, loaded...)
+	}
+
+	errs = append(errs, checkDuplicateManifests(manifests)...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return manifests, nil
+}
+
+// ParseManifests parses the manifests in a YAML or JSON document. The file name is only used in errors.
+// YAML files may hold several documents separated by ---, and a document may be a list of manifests
+func ParseManifests(file string, data []byte) ([]*Manifest, error) {
+	var manifests []*Manifest
+	var errs ManifestErrors
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, &ManifestError{File: file, Message: err.Error()})
+			break
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		root := doc.Content[0]
+		items := []*yaml.Node{root}
+		if root.Kind == yaml.SequenceNode {
+			items = root.Content
+		}
+		for _, item := range items {
+			manifest, itemErrs := parseManifest(file, item)
+			errs = append(errs, itemErrs...)
+			if manifest != nil {
+				manifests = append(manifests, manifest)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return manifests, nil
+}
+
+// parseManifest decodes and validates a single manifest node
+func parseManifest(file string, node *yaml.Node) (*Manifest, ManifestErrors) {
+	var errs ManifestErrors
+	fail := func(at *yaml.Node, format string, args ...interface{}) {
+		errs = append(errs, &ManifestError{File: file, Line: at.Line, Column: at.Column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if node.Kind != yaml.MappingNode {
+		fail(node, "manifest must be a mapping")
+		return nil, errs
+	}
+	checkKnownFields(node, []string{"apiVersion", "kind", "metadata", "config", "offsets"}, fail)
+	if metadata := mappingValue(node, "metadata"); metadata != nil {
+		checkKnownFields(metadata, []string{"name", "labels", "cluster", "state"}, fail)
+	}
+	if config := mappingValue(node, "config"); config != nil && config.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(config.Content); i += 2 {
+			if value := config.Content[i+1]; value.Kind != yaml.ScalarNode {
+				fail(value, "config value of %v must be a scalar", config.Content[i].Value)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	manifest := new(Manifest)
+	if err := node.Decode(manifest); err != nil {
+		fail(node, "%v", err)
+		return nil, errs
+	}
+	manifest.Source = fmt.Sprintf("%v:%d", file, node.Line)
+
+	at := func(path ...string) *yaml.Node {
+		current := node
+		for _, key := range path {
+			next := mappingValue(current, key)
+			if next == nil {
+				return current
+			}
+			current = next
+		}
+		return current
+	}
+	switch {
+	case manifest.APIVersion == "":
+		fail(node, "apiVersion is required")
+	case manifest.APIVersion != ManifestAPIVersion:
+		fail(at("apiVersion"), "unsupported apiVersion %q, expected %q", manifest.APIVersion, ManifestAPIVersion)
+	}
+	if manifest.Kind != ManifestKind {
+		fail(at("kind"), "unsupported kind %q, expected %q", manifest.Kind, ManifestKind)
+	}
+	if manifest.Metadata.Name == "" {
+		fail(at("metadata"), "metadata.name is required")
+	}
+	switch manifest.Metadata.State {
+	case "", StateRunning, StatePaused, StateStopped:
+	default:
+		fail(at("metadata", "state"), "unknown state %q, expected running, paused or stopped", manifest.Metadata.State)
+	}
+	if len(manifest.Config) == 0 {
+		fail(at("config"), "config is required")
+	}
+	for i, offset := range manifest.Offsets {
+		if len(offset.Partition) == 0 {
+			fail(at("offsets"), "offsets[%d].partition is required", i)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return manifest, nil
+}
+
+// checkDuplicateManifests reports connectors defined more than once for the same cluster
+func checkDuplicateManifests(manifests []*Manifest) ManifestErrors {
+	var errs ManifestErrors
+	seen := map[string]*Manifest{}
+	for _, manifest := range manifests {
+		key := manifest.Metadata.Cluster + "/" + manifest.Metadata.Name
+		if first, ok := seen[key]; ok {
+			errs = append(errs, &ManifestError{
+				File:    manifest.Source,
+				Message: fmt.Sprintf("connector %v is already defined at %v", manifest.Metadata.Name, first.Source),
+			})
+			continue
+		}
+		seen[key] = manifest
+	}
+	return errs
+}
+
+// ManifestsForCluster returns the manifests targeting the named cluster, including the ones without a cluster
+func ManifestsForCluster(manifests []*Manifest, cluster string) []*Manifest {
+	var selected []*Manifest
+	for _, manifest := range manifests {
+		if manifest.Metadata.Cluster == "" || manifest.Metadata.Cluster == cluster {
+			selected = append(selected, manifest)
+		}
+	}
+	return selected
+}
+
+// ManifestRequests returns the connector requests of the manifests, e.g. to pass them to a Reconciler
+func ManifestRequests(manifests []*Manifest) []ConnectorRequest {
+	requests := make([]ConnectorRequest, len(manifests))
+	for i, manifest := range manifests {
+		requests[i] = manifest.ConnectorRequest()
+	}
+	return requests
+}
+
+// mappingValue returns the value node of the key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func checkKnownFields(node *yaml.Node, known []string, fail func(*yaml.Node, string, ...interface{})) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		found := false
+		for _, name := range known {
+			if key.Value == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail(key, "unknown field %q", key.Value)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package connect
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testManifests = `apiVersion: kafka-connect-go/v1
+kind: Connector
+metadata:
+  name: users-replicator
+  labels:
+    team: data
+  cluster: dc2
+  state: paused
+config:
+  connector.class: io.confluent.connect.replicator.ReplicatorSourceConnector
+  confluent.topic.replication.factor: 1
+offsets:
+  - partition: {topic: users, partition: 0}
+    offset: {offset: 42}
+---
+apiVersion: kafka-connect-go/v1
+kind: Connector
+metadata:
+  name: orders-replicator
+config:
+  connector.class: io.confluent.connect.replicator.ReplicatorSourceConnector
+`
+
+func TestParseManifests(t *testing.T) {
+	manifests, err := ParseManifests("connectors.yaml", []byte(testManifests))
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 2)
+
+	users := manifests[0]
+	assert.Equal(t, "users-replicator", users.Metadata.Name)
+	assert.Equal(t, map[string]string{"team": "data"}, users.Metadata.Labels)
+	assert.Equal(t, StatePaused, users.DesiredState())
+	assert.Equal(t, "PAUSED", users.ConnectorRequest().InitialState)
+	assert.Equal(t, "connectors.yaml:1", users.Source)
+	assert.Equal(t, 1, users.Config["confluent.topic.replication.factor"])
+	assert.Equal(t, 42, users.Offsets[0].Offset["offset"])
+
+	orders := manifests[1]
+	assert.Equal(t, StateRunning, orders.DesiredState())
+	assert.Equal(t, "connectors.yaml:16", orders.Source)
+	assert.Equal(t, ConnectorRequest{Name: "orders-replicator", Config: orders.Config}, orders.ConnectorRequest())
+
+	assert.Len(t, ManifestsForCluster(manifests, "dc1"), 1)
+}
+
+func TestParseManifests_JSONList(t *testing.T) {
+	manifests, err := ParseManifests("connectors.json", []byte(`[
+  {"apiVersion": "kafka-connect-go/v1", "kind": "Connector", "metadata": {"name": "a"}, "config": {"tasks.max": "1"}},
+  {"apiVersion": "kafka-connect-go/v1", "kind": "Connector", "metadata": {"name": "b"}, "config": {"tasks.max": "1"}}
+]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []ConnectorRequest{
+		{Name: "a", Config: map[string]interface{}{"tasks.max": "1"}},
+		{Name: "b", Config: map[string]interface{}{"tasks.max": "1"}},
+	}, ManifestRequests(manifests))
+}
+
+func TestParseManifests_Errors(t *testing.T) {
+	_, err := ParseManifests("bad.yaml", []byte(`apiVersion: kafka-connect-go/v2
+kind: Connector
+metadata:
+  name: a
+  state: sleeping
+  owner: me
+config:
+  nested:
+    key: value
+`))
+	assert.Equal(t, `bad.yaml:6:3: unknown field "owner"
+bad.yaml:9:5: config value of nested must be a scalar`, err.Error())
+
+	_, err = ParseManifests("bad.yaml", []byte(`apiVersion: kafka-connect-go/v2
+kind: Connector
+metadata:
+  state: sleeping
+config: {}
+`))
+	assert.Equal(t, `bad.yaml:1:13: unsupported apiVersion "kafka-connect-go/v2", expected "kafka-connect-go/v1"
+bad.yaml:4:3: metadata.name is required
+bad.yaml:4:10: unknown state "sleeping", expected running, paused or stopped
+bad.yaml:5:9: config is required`, err.Error())
+}
+
+func TestLoadManifests_Directory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifests")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "team"), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.yaml"), []byte(testManifests), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "team", "b.yml"), []byte(testManifests), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0600))
+
+	_, err = LoadManifests(dir)
+	assert.Error(t, err)
+	errs, ok := err.(ManifestErrors)
+	assert.True(t, ok)
+	assert.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), "team/b.yml:1: connector users-replicator is already defined at "+filepath.Join(dir, "a.yaml")+":1")
+
+	assert.NoError(t, os.Remove(filepath.Join(dir, "team", "b.yml")))
+	manifests, err := LoadManifests(dir)
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 2)
+}
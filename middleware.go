@@ -0,0 +1,101 @@
+package connect
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestInfo describes a single attempt of a request made by the client.
+// The response fields are only set when it is passed to Middleware.After
+type RequestInfo struct {
+	// Operation is the client operation, e.g. "get connector status", the same as APIError.Operation
+	Operation string
+	// Connector is the name of the connector the request is about, empty for cluster wide requests
+	Connector string
+	Method    string
+	Path      string
+	// Attempt starts at 1 and is incremented every time the client retries the request
+	Attempt int
+
+	// StatusCode is the status of the response, 0 when no response was received
+	StatusCode int
+	Latency    time.Duration
+	// Err is the transport error, requests answered with an error status have a StatusCode >= 400 instead
+	Err error
+}
+
+// Middleware observes every request made by the client, see NewConnect
+type Middleware interface {
+	// Before is called before the request is sent, the returned context is used for the request and passed to After
+	Before(ctx context.Context, info *RequestInfo) context.Context
+	// After is called once the response is received or the request failed
+	After(ctx context.Context, info *RequestInfo)
+}
+
+// MiddlewareFuncs is a Middleware calling its functions, either of which can be nil
+type MiddlewareFuncs struct {
+	BeforeFunc func(ctx context.Context, info *RequestInfo) context.Context
+	AfterFunc  func(ctx context.Context, info *RequestInfo)
+}
+
+// Before implements Middleware
+func (m MiddlewareFuncs) Before(ctx context.Context, info *RequestInfo) context.Context {
+	if m.BeforeFunc == nil {
+		return ctx
+	}
+	return m.BeforeFunc(ctx, info)
+}
+
+// After implements Middleware
+func (m MiddlewareFuncs) After(ctx context.Context, info *RequestInfo) {
+	if m.AfterFunc != nil {
+		m.AfterFunc(ctx, info)
+	}
+}
+
+type operationKey struct{}
+
+// operation is stored in the context of a request to describe it to the middlewares
+type operation struct {
+	name      string
+	connector string
+	attempts  int
+}
+
+// withOperation returns a context describing the request of an operation
+func withOperation(ctx context.Context, name, connector string) context.Context {
+	return context.WithValue(ctx, operationKey{}, &operation{name: name, connector: connector})
+}
+
+// middlewareTransport calls the middlewares around every attempt of a request
+type middlewareTransport struct {
+	next        http.RoundTripper
+	middlewares []Middleware
+}
+
+func (t *middlewareTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	info := &RequestInfo{Method: req.Method, Path: req.URL.Path, Attempt: 1}
+	if op, ok := req.Context().Value(operationKey{}).(*operation); ok {
+		// the context is shared by the retries of the request
+		op.attempts++
+		info.Operation, info.Connector, info.Attempt = op.name, op.connector, op.attempts
+	}
+
+	ctx := req.Context()
+	for _, middleware := range t.middlewares {
+		ctx = middleware.Before(ctx, info)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	info.Latency = time.Since(start)
+	info.Err = err
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+	}
+	for i := len(t.middlewares) - 1; i >= 0; i-- {
+		t.middlewares[i].After(ctx, info)
+	}
+	return resp, err
+}
@@ -0,0 +1,325 @@
+package connect
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+)
+
+// ConfigRewrite changes the config values of the matching keys while migrating a connector
+type ConfigRewrite struct {
+	// Key is a glob pattern on the config keys, e.g. "*bootstrap.servers"
+	Key string
+	// Old is replaced by New in the values, the whole value is replaced by New when Old is empty
+	Old string
+	New string
+}
+
+// MigrateOptions configures a migration
+type MigrateOptions struct {
+	// Include and Exclude are glob patterns on the connector names, every connector is migrated when Include is empty
+	Include []string
+	Exclude []string
+	// Rewrites are applied in order to the config copied to the destination
+	Rewrites []ConfigRewrite
+	// SkipOffsets does not transfer the offsets, the connectors start from their configured initial position
+	SkipOffsets bool
+	// VerifyTimeout is how long to wait for a migrated connector and its tasks to reach their state, one minute by default
+	VerifyTimeout time.Duration
+	// PollInterval is the time between two status checks while verifying, two seconds by default
+	PollInterval time.Duration
+	// DeleteSource deletes the source connector once migrated, it is left stopped otherwise
+	DeleteSource bool
+	// KeepFailed leaves the source connector stopped and the destination connector in place when a migration
+	// fails, e.g. to investigate the failure. By default the destination connector is deleted and the source
+	// connector is restored to its state
+	KeepFailed bool
+	// DryRun reports what would be migrated without changing either cluster
+	DryRun bool
+}
+
+// MigrateResult is the outcome of migrating a single connector
+type MigrateResult struct {
+	Name    string
+	State   ConnectorState
+	Offsets int
+	// Changes are the config values changed by the rewrites
+	Changes    []ConfigChange
+	RolledBack bool
+	Err        error
+}
+
+// MigrateReport is the outcome of a migration
+type MigrateReport struct {
+	DryRun  bool
+	Results []MigrateResult
+}
+
+// String renders the report one connector per line, followed by its rewritten config values
+func (r *MigrateReport) String() string {
+	var buf bytes.Buffer
+	verb := "migrated"
+	if r.DryRun {
+		verb = "would migrate"
+	}
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil && result.RolledBack:
+			fmt.Fprintf(&buf, "failed %v, rolled back: %v\n", result.Name, result.Err)
+		case result.Err != nil:
+			fmt.Fprintf(&buf, "failed %v: %v\n", result.Name, result.Err)
+		default:
+			fmt.Fprintf(&buf, "%v %v (%v, %d offsets)\n", verb, result.Name, result.State, result.Offsets)
+		}
+		for _, change := range result.Changes {
+			fmt.Fprintf(&buf, "    %v\n", change)
+		}
+	}
+	return buf.String()
+}
+
+// Err returns an error summarising the connectors that could not be migrated, or nil if there are none
+func (r *MigrateReport) Err() error {
+	var failed []MigrateResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.Errorf("migrate error: %d of %d connectors failed, first error on %v: %v",
+		len(failed), len(r.Results), failed[0].Name, failed[0].Err)
+}
+
+// Migrate moves connectors from the src cluster to the dst cluster, one at a time.
+// Each source connector is stopped, its config copied with the rewrites applied, its offsets transferred
+// when both workers support the offsets API, and it is started on the destination in its original state.
+// The migration of a connector succeeds once it and at least one task, all of them in that state, run on the
+// destination. A failed migration is rolled back unless KeepFailed is set.
+// Connectors that already exist on the destination are reported as failed without touching the source
+func Migrate(src, dst Connect, options MigrateOptions) (*MigrateReport, error) {
+	if options.VerifyTimeout <= 0 {
+		options.VerifyTimeout = time.Minute
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = 2 * time.Second
+	}
+
+	connectors, err := src.GetConnectors()
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate error: could not list source connectors")
+	}
+	existing, err := dst.GetConnectors()
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate error: could not list destination connectors")
+	}
+	onDestination := make(map[string]bool, len(existing.Connectors))
+	for _, name := range existing.Connectors {
+		onDestination[name] = true
+	}
+
+	names := append([]string{}, connectors.Connectors...)
+	sort.Strings(names)
+	report := &MigrateReport{DryRun: options.DryRun}
+	for _, name := range names {
+		if !matchNames(name, options.Include, options.Exclude) {
+			continue
+		}
+		result := MigrateResult{Name: name}
+		if onDestination[name] {
+			result.Err = errors.New("connector already exists on the destination")
+		} else {
+			m := &migration{src: src, dst: dst, options: options, result: &result}
+			result.Err = m.run()
+		}
+		if result.Err != nil {
+			logger.WithError(result.Err).Errorf("Could not migrate connector %v", name)
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// migration migrates a single connector and remembers what to undo
+type migration struct {
+	src, dst Connect
+	options  MigrateOptions
+	result   *MigrateResult
+
+	sourceState ConnectorState
+	stopped     bool
+	created     bool
+}
+
+func (m *migration) run() error {
+	name := m.result.Name
+	config, err := m.src.GetConnectorConfig(name)
+	if err != nil {
+		return errors.Wrap(err, "could not get source config")
+	}
+	status, err := m.src.GetConnectorStatus(name)
+	if err != nil {
+		return errors.Wrap(err, "could not get source status")
+	}
+	m.sourceState = stateOf(status)
+	m.result.State = m.sourceState
+
+	rewritten := RewriteConfig(config.Config, m.options.Rewrites)
+	m.result.Changes = DiffConfig(rewritten, config.Config)
+	if m.options.DryRun {
+		offsets, err := m.sourceOffsets()
+		m.result.Offsets = len(offsets)
+		return err
+	}
+
+	if err := m.stopSource(); err != nil {
+		return m.fail(errors.Wrap(err, "could not stop source connector"))
+	}
+	// the offsets are final once the source is stopped
+	offsets, err := m.sourceOffsets()
+	if err != nil {
+		return m.fail(err)
+	}
+	m.result.Offsets = len(offsets)
+
+	m.created, err = restoreConnector(m.dst, ConnectorBackup{Name: name, Config: rewritten, State: m.sourceState}, offsets)
+	if err != nil {
+		return m.fail(err)
+	}
+
+	if err := m.verify(); err != nil {
+		return m.fail(err)
+	}
+	if m.options.DeleteSource {
+		if _, err := m.src.DeleteConnector(name); err != nil {
+			return errors.Wrap(err, "migrated but could not delete source connector")
+		}
+	}
+	return nil
+}
+
+// sourceOffsets returns the offsets of the source connector, or none if they are skipped or not supported
+func (m *migration) sourceOffsets() ([]ConnectorOffset, error) {
+	if m.options.SkipOffsets {
+		return nil, nil
+	}
+	response, err := m.src.GetConnectorOffsets(m.result.Name)
+	if offsetsNotSupported(err) {
+		logger.WithError(err).Warnf("Offsets are not supported by the source worker, migrating %v without offsets", m.result.Name)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get source offsets")
+	}
+	return response.Offsets, nil
+}
+
+// stopSource stops the source connector, pausing it on workers older than kafka 3.5
+func (m *migration) stopSource() error {
+	_, err := m.src.StopConnector(m.result.Name)
+	if offsetsNotSupported(err) {
+		// the stop endpoint was added with the offsets endpoint
+		_, err = m.src.PauseConnector(m.result.Name)
+	}
+	if err == nil {
+		m.stopped = true
+	}
+	return err
+}
+
+// verify waits for the destination connector and its tasks to reach the source state
+func (m *migration) verify() error {
+	want := strings.ToUpper(string(m.sourceState))
+	deadline := time.Now().Add(m.options.VerifyTimeout)
+	for {
+		status, err := m.dst.GetConnectorStatus(m.result.Name)
+		if err == nil {
+			state := status.ConnectorStatus["state"]
+			if state == "FAILED" {
+				return errors.Errorf("destination connector FAILED: %v", firstTraceLine(status.ConnectorStatus["trace"]))
+			}
+			// stopped connectors have no tasks, the others are only ready once their tasks are assigned
+			ready := state == want && (m.sourceState == StateStopped || len(status.TasksStatus) > 0)
+			for _, task := range status.TasksStatus {
+				if task.State == "FAILED" {
+					return errors.Errorf("destination task %d FAILED: %v", task.ID, firstTraceLine(task.Trace))
+				}
+				ready = ready && task.State == want
+			}
+			if ready {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return errors.Wrapf(err, "destination connector did not reach %v within %v", want, m.options.VerifyTimeout)
+			}
+			return errors.Errorf("destination connector did not reach %v within %v", want, m.options.VerifyTimeout)
+		}
+		time.Sleep(m.options.PollInterval)
+	}
+}
+
+// fail rolls back the migration unless KeepFailed is set and returns the error
+func (m *migration) fail(err error) error {
+	name := m.result.Name
+	if m.options.KeepFailed {
+		if !m.stopped {
+			return err
+		}
+		logger.WithError(err).Errorf("Migration of %v failed, the source connector is left stopped, it was %v", name, m.sourceState)
+		return errors.Wrapf(err, "source connector left stopped, it was %v", m.sourceState)
+	}
+	if m.created {
+		if _, rollbackErr := m.dst.DeleteConnector(name); rollbackErr != nil {
+			return errors.Wrapf(err, "rollback failed, could not delete destination connector: %v", rollbackErr)
+		}
+	}
+	if m.stopped {
+		var rollbackErr error
+		switch m.sourceState {
+		case StateRunning:
+			_, rollbackErr = m.src.ResumeConnector(name)
+		case StatePaused:
+			_, rollbackErr = m.src.PauseConnector(name)
+		}
+		if rollbackErr != nil {
+			return errors.Wrapf(err, "rollback failed, could not restore source connector: %v", rollbackErr)
+		}
+	}
+	m.result.RolledBack = true
+	return err
+}
+
+// RewriteConfig returns a copy of the config with the rewrites applied in order
+func RewriteConfig(config map[string]interface{}, rewrites []ConfigRewrite) map[string]interface{} {
+	rewritten := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		for _, rewrite := range rewrites {
+			if !globMatch(rewrite.Key, key) {
+				continue
+			}
+			if rewrite.Old == "" {
+				value = rewrite.New
+			} else if s, ok := value.(string); ok {
+				value = strings.Replace(s, rewrite.Old, rewrite.New, -1)
+			}
+		}
+		rewritten[key] = value
+	}
+	return rewritten
+}
+
+func firstTraceLine(trace string) string {
+	if i := strings.IndexByte(trace, '\n'); i >= 0 {
+		return trace[:i]
+	}
+	return trace
+}
@@ -0,0 +1,118 @@
+package connect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingTasks is a cluster whose connectors start with a failed task
+type failingTasks struct {
+	*fakeConnect
+}
+
+func (f failingTasks) GetConnectorStatus(name string) (*GetConnectorStatusResponse, error) {
+	status, err := f.fakeConnect.GetConnectorStatus(name)
+	if err == nil {
+		status.TasksStatus = []TaskStatus{{ID: 0, State: "FAILED", Trace: "org.apache.kafka.common.KafkaException: boom\n\tat Worker.java"}}
+	}
+	return status, err
+}
+
+// runningTasks is a cluster whose connectors that are not stopped have a single task in their state
+type runningTasks struct {
+	*fakeConnect
+}
+
+func (f runningTasks) GetConnectorStatus(name string) (*GetConnectorStatusResponse, error) {
+	status, err := f.fakeConnect.GetConnectorStatus(name)
+	if err == nil && status.ConnectorStatus["state"] != "STOPPED" {
+		status.TasksStatus = []TaskStatus{{ID: 0, State: status.ConnectorStatus["state"]}}
+	}
+	return status, err
+}
+
+func newMigrateSource() *fakeConnect {
+	src := newFakeConnect()
+	src.add("db-source", map[string]interface{}{
+		"connector.class":                     "JdbcSourceConnector",
+		"producer.override.bootstrap.servers": "old-broker-1:9092,old-broker-2:9092",
+	})
+	src.offsets["db-source"] = []ConnectorOffset{{
+		Partition: map[string]interface{}{"table": "users"},
+		Offset:    map[string]interface{}{"incrementing": float64(42)},
+	}}
+	src.add("s3-sink", map[string]interface{}{"connector.class": "S3SinkConnector"})
+	src.PauseConnector("s3-sink")
+	src.calls = nil
+	return src
+}
+
+var migrateRewrites = []ConfigRewrite{{Key: "*bootstrap.servers", Old: "old-", New: "new-"}}
+
+func TestMigrate(t *testing.T) {
+	src, dst := newMigrateSource(), newFakeConnect()
+	offsets := src.offsets["db-source"]
+
+	report, err := Migrate(src, dst, MigrateOptions{Rewrites: migrateRewrites, DryRun: true})
+	assert.NoError(t, err)
+	assert.Empty(t, src.calls)
+	assert.Empty(t, dst.calls)
+	assert.Equal(t, `would migrate db-source (running, 1 offsets)
+    ~ producer.override.bootstrap.servers: "old-broker-1:9092,old-broker-2:9092" => "new-broker-1:9092,new-broker-2:9092"
+would migrate s3-sink (paused, 0 offsets)
+`, report.String())
+
+	report, err = Migrate(src, runningTasks{dst}, MigrateOptions{Rewrites: migrateRewrites, DeleteSource: true, PollInterval: time.Millisecond})
+	assert.NoError(t, err)
+	assert.NoError(t, report.Err())
+	assert.Equal(t, []string{"stop db-source", "delete db-source", "stop s3-sink", "delete s3-sink"}, src.calls)
+	assert.Equal(t, []string{
+		"create db-source initial_state=STOPPED",
+		"alter offsets db-source (1)",
+		"resume db-source",
+		"create s3-sink initial_state=PAUSED",
+		"pause s3-sink",
+	}, dst.calls)
+	assert.Equal(t, "new-broker-1:9092,new-broker-2:9092", dst.connectors["db-source"]["producer.override.bootstrap.servers"])
+	assert.Equal(t, "PAUSED", dst.states["s3-sink"])
+	assert.Equal(t, offsets, dst.offsets["db-source"])
+}
+
+func TestMigrate_Rollback(t *testing.T) {
+	src, dst := newMigrateSource(), newFakeConnect()
+	dst.add("s3-sink", nil)
+	dst.calls = nil
+
+	report, err := Migrate(src, failingTasks{dst}, MigrateOptions{PollInterval: time.Millisecond})
+	assert.NoError(t, err)
+	assert.Equal(t, `failed db-source, rolled back: destination task 0 FAILED: org.apache.kafka.common.KafkaException: boom
+failed s3-sink: connector already exists on the destination
+`, report.String())
+	assert.EqualError(t, report.Err(), "migrate error: 2 of 2 connectors failed, first error on db-source: destination task 0 FAILED: org.apache.kafka.common.KafkaException: boom")
+	assert.Equal(t, []string{"stop db-source", "resume db-source"}, src.calls)
+	assert.Equal(t, "delete db-source", dst.calls[len(dst.calls)-1])
+	assert.Equal(t, "RUNNING", src.states["db-source"])
+	_, exists := dst.connectors["db-source"]
+	assert.False(t, exists)
+}
+
+func TestMigrate_KeepFailed(t *testing.T) {
+	src, dst := newMigrateSource(), newFakeConnect()
+
+	// the destination connector is RUNNING without tasks, which is not a successful migration
+	report, err := Migrate(src, dst, MigrateOptions{
+		Include:       []string{"db-source"},
+		KeepFailed:    true,
+		VerifyTimeout: 10 * time.Millisecond,
+		PollInterval:  time.Millisecond,
+	})
+	assert.NoError(t, err)
+	assert.EqualError(t, report.Err(), "migrate error: 1 of 1 connectors failed, first error on db-source: "+
+		"source connector left stopped, it was running: destination connector did not reach RUNNING within 10ms")
+	assert.False(t, report.Results[0].RolledBack)
+	assert.Equal(t, []string{"stop db-source"}, src.calls)
+	assert.Equal(t, "STOPPED", src.states["db-source"])
+	assert.Equal(t, "RUNNING", dst.states["db-source"])
+}
@@ -0,0 +1,102 @@
+package connect
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NormalizeConfigValue converts a config value to the canonical form of the string connect stores for it.
+// Only values that are entirely a number, a boolean or a comma separated list are rewritten: numbers lose the
+// trailing zeros of their fraction so that 1, 1.0, "1.0" and json.Number("1.0") are equal, booleans are lower
+// case, and lists are joined with commas without whitespace around their items, as connect does when it parses
+// a list. A list is a value whose items are all names such as topics, classes, host:port pairs or urls, so a
+// change inside a string such as a query or a jaas config is never hidden. Other values are only trimmed
+func NormalizeConfigValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return normalizeString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case json.Number:
+		return normalizeString(v.String())
+	case []string:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = normalizeString(item)
+		}
+		return strings.Join(values, ",")
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = NormalizeConfigValue(item)
+		}
+		return strings.Join(values, ",")
+	default:
+		return normalizeString(fmt.Sprint(v))
+	}
+}
+
+var (
+	// decimal matches the values that are entirely a decimal number
+	decimal = regexp.MustCompile(`^[+-]?[0-9]+\.[0-9]+$`)
+	// listItem matches the items of the values that are comma separated lists
+	listItem = regexp.MustCompile(`^[A-Za-z0-9_.\-:/]+$`)
+)
+
+func normalizeString(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+		return strings.ToLower(value)
+	}
+	if decimal.MatchString(value) {
+		// the digits are kept as they are rather than parsed, which would round the long ones
+		return strings.TrimSuffix(strings.TrimRight(value, "0"), ".")
+	}
+	if !strings.Contains(value, ",") {
+		return value
+	}
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+		if !listItem.MatchString(items[i]) {
+			return value
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// NormalizeConfig returns the config with every value normalised by NormalizeConfigValue
+func NormalizeConfig(config map[string]interface{}) map[string]string {
+	normalized := make(map[string]string, len(config))
+	for key, value := range config {
+		normalized[key] = NormalizeConfigValue(value)
+	}
+	return normalized
+}
+
+// ConfigEqual reports whether the desired config matches the config returned by connect,
+// with the same rules as DiffConfig: values are normalised and the name key connect adds is ignored
+// unless it is part of the desired config
+func ConfigEqual(desired, actual map[string]interface{}) bool {
+	for key, value := range desired {
+		actualValue, ok := actual[key]
+		if !ok || NormalizeConfigValue(actualValue) != NormalizeConfigValue(value) {
+			return false
+		}
+	}
+	for key := range actual {
+		if _, ok := desired[key]; !ok && key != "name" {
+			return false
+		}
+	}
+	return true
+}
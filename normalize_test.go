@@ -0,0 +1,73 @@
+package connect
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeConfigValue(t *testing.T) {
+	for value, want := range map[interface{}]string{
+		nil:                                    "",
+		1:                                      "1",
+		int64(3):                               "3",
+		1.0:                                    "1",
+		0.5:                                    "0.5",
+		json.Number("10"):                      "10",
+		json.Number("10.0"):                    "10",
+		json.Number("0.50"):                    "0.5",
+		"1.0":                                  "1",
+		"-2.500":                               "-2.5",
+		"100":                                  "100",
+		"1.0.0":                                "1.0.0",
+		true:                                   "true",
+		"TRUE":                                 "true",
+		" False ":                              "false",
+		" users ":                              "users",
+		"users, orders ,payments":              "users,orders,payments",
+		"broker-1:9092, broker-2:9092":         "broker-1:9092,broker-2:9092",
+		"a,,b":                                 "a,,b",
+		"$1, $2":                               "$1, $2",
+		"^(users|orders)-.*$":                  "^(users|orders)-.*$",
+		"SELECT a, b FROM t WHERE flag = TRUE": "SELECT a, b FROM t WHERE flag = TRUE",
+	} {
+		assert.Equal(t, want, NormalizeConfigValue(value), "%#v", value)
+	}
+	assert.Equal(t, "users,orders", NormalizeConfigValue([]string{"users", " orders"}))
+	assert.Equal(t, NormalizeConfigValue(1.0), NormalizeConfigValue("1.0"))
+	assert.Equal(t, NormalizeConfigValue(json.Number("10.0")), NormalizeConfigValue(10))
+	assert.Equal(t, "1,true,a", NormalizeConfigValue([]interface{}{1, true, "a"}))
+}
+
+func TestConfigEqual(t *testing.T) {
+	desired := map[string]interface{}{
+		"connector.class":                    "A",
+		"confluent.topic.replication.factor": 1,
+		"provenance.header.enable":           true,
+		"topic.whitelist":                    []string{"users", "orders"},
+	}
+	actual := map[string]interface{}{
+		"name":                               "test",
+		"connector.class":                    "A",
+		"confluent.topic.replication.factor": "1",
+		"provenance.header.enable":           "true",
+		"topic.whitelist":                    "users, orders",
+	}
+	assert.True(t, ConfigEqual(desired, actual))
+	assert.Empty(t, DiffConfig(desired, actual))
+
+	assert.True(t, ConfigEqual(map[string]interface{}{"topics": "a,b"}, map[string]interface{}{"topics": "a, b"}))
+	assert.Empty(t, DiffConfig(map[string]interface{}{"topics": "a, b"}, map[string]interface{}{"topics": "a,b"}))
+
+	// the whitespace and case inside a string are significant
+	queries := map[string]interface{}{"query": "SELECT a,b FROM t WHERE flag = TRUE"}
+	assert.False(t, ConfigEqual(queries, map[string]interface{}{"query": "SELECT a, b FROM t WHERE flag = TRUE"}))
+	assert.False(t, ConfigEqual(queries, map[string]interface{}{"query": "SELECT a,b FROM t WHERE flag = true"}))
+
+	actual["tasks.max"] = "1"
+	assert.False(t, ConfigEqual(desired, actual))
+	delete(actual, "tasks.max")
+	desired["name"] = "other"
+	assert.False(t, ConfigEqual(desired, actual))
+}
@@ -0,0 +1,110 @@
+// Package otelconnect provides OpenTelemetry tracing and metrics middlewares for the kafka connect client
+//
+//	tracing := otelconnect.NewTracingMiddleware(otel.GetTracerProvider())
+//	metrics, err := otelconnect.NewMetricsMiddleware(otel.GetMeterProvider())
+//	client := connect.NewConnect("localhost:8083", tracing, metrics)
+package otelconnect
+
+import (
+	"context"
+	"fmt"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer and the meter
+const ScopeName = "github.com/kevinsamoei/kafka-connect-go/otelconnect"
+
+// attribute keys set on the spans and the measurements
+const (
+	OperationKey  = attribute.Key("kafka_connect.operation")
+	ConnectorKey  = attribute.Key("kafka_connect.connector")
+	AttemptKey    = attribute.Key("kafka_connect.attempt")
+	MethodKey     = attribute.Key("http.request.method")
+	PathKey       = attribute.Key("url.path")
+	StatusCodeKey = attribute.Key("http.response.status_code")
+)
+
+// tracingMiddleware starts a client span for every attempt of a request
+type tracingMiddleware struct {
+	tracer trace.Tracer
+}
+
+// NewTracingMiddleware returns a middleware recording every request as a span named after its operation,
+// e.g. "get connector status", with the connector name and the attempt as attributes
+func NewTracingMiddleware(provider trace.TracerProvider) connect.Middleware {
+	return &tracingMiddleware{tracer: provider.Tracer(ScopeName)}
+}
+
+func (m *tracingMiddleware) Before(ctx context.Context, info *connect.RequestInfo) context.Context {
+	name := info.Operation
+	if name == "" {
+		name = info.Method
+	}
+	attributes := []attribute.KeyValue{
+		OperationKey.String(info.Operation),
+		MethodKey.String(info.Method),
+		PathKey.String(info.Path),
+		AttemptKey.Int(info.Attempt),
+	}
+	if info.Connector != "" {
+		attributes = append(attributes, ConnectorKey.String(info.Connector))
+	}
+	ctx, _ = m.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	return ctx
+}
+
+func (m *tracingMiddleware) After(ctx context.Context, info *connect.RequestInfo) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+	if info.Err != nil {
+		span.RecordError(info.Err)
+		span.SetStatus(codes.Error, info.Err.Error())
+		return
+	}
+	span.SetAttributes(StatusCodeKey.Int(info.StatusCode))
+	if info.StatusCode >= 400 {
+		span.SetStatus(codes.Error, fmt.Sprintf("status code %d", info.StatusCode))
+	}
+}
+
+// metricsMiddleware records the latency and the errors of the requests
+type metricsMiddleware struct {
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+// NewMetricsMiddleware returns a middleware recording the latency of the requests per operation in the
+// kafka_connect.client.request.duration histogram, and the failed requests per operation and status code
+// in the kafka_connect.client.request.errors counter
+func NewMetricsMiddleware(provider metric.MeterProvider) (connect.Middleware, error) {
+	meter := provider.Meter(ScopeName)
+	duration, err := meter.Float64Histogram("kafka_connect.client.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Latency of the requests made to the connect rest API."))
+	if err != nil {
+		return nil, err
+	}
+	errors, err := meter.Int64Counter("kafka_connect.client.request.errors",
+		metric.WithDescription("Number of failed requests to the connect rest API, the status code is 0 when no response was received."))
+	if err != nil {
+		return nil, err
+	}
+	return &metricsMiddleware{duration: duration, errors: errors}, nil
+}
+
+func (m *metricsMiddleware) Before(ctx context.Context, info *connect.RequestInfo) context.Context {
+	return ctx
+}
+
+func (m *metricsMiddleware) After(ctx context.Context, info *connect.RequestInfo) {
+	operation := OperationKey.String(info.Operation)
+	m.duration.Record(ctx, info.Latency.Seconds(), metric.WithAttributes(operation))
+	if info.Err != nil || info.StatusCode >= 400 {
+		m.errors.Add(ctx, 1, metric.WithAttributes(operation, StatusCodeKey.Int(info.StatusCode)))
+	}
+}
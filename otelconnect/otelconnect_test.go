@@ -0,0 +1,67 @@
+package otelconnect
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	connect "github.com/kevinsamoei/kafka-connect-go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMiddlewares(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_code":409,"message":"rebalance in process"}`))
+			return
+		}
+		w.Write([]byte(`{"name":"db","connector":{"state":"RUNNING"},"tasks":[]}`))
+	}))
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	metrics, err := NewMetricsMiddleware(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	assert.NoError(t, err)
+	client := connect.NewConnect(strings.TrimPrefix(server.URL, "http://"),
+		NewTracingMiddleware(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))), metrics)
+
+	_, err = client.GetConnectorStatus("db")
+	assert.NoError(t, err)
+	_, err = client.PauseConnector("db")
+	assert.Error(t, err)
+
+	ended := spans.Ended()
+	assert.Equal(t, 2, len(ended))
+	assert.Equal(t, "get connector status", ended[0].Name())
+	assert.Contains(t, ended[0].Attributes(), ConnectorKey.String("db"))
+	assert.Contains(t, ended[0].Attributes(), PathKey.String("/connectors/db/status"))
+	assert.Contains(t, ended[0].Attributes(), StatusCodeKey.Int(200))
+	assert.Equal(t, codes.Unset, ended[0].Status().Code)
+	assert.Equal(t, "pause connector", ended[1].Name())
+	assert.Equal(t, codes.Error, ended[1].Status().Code)
+
+	var data metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &data))
+	found := map[string]metricdata.Aggregation{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = m.Data
+		}
+	}
+	assert.Equal(t, 2, len(found["kafka_connect.client.request.duration"].(metricdata.Histogram[float64]).DataPoints))
+	errors := found["kafka_connect.client.request.errors"].(metricdata.Sum[int64]).DataPoints
+	assert.Equal(t, 1, len(errors))
+	assert.Equal(t, int64(1), errors[0].Value)
+	assert.Equal(t, attribute.NewSet(OperationKey.String("pause connector"), StatusCodeKey.Int(409)), errors[0].Attributes)
+}
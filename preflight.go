@@ -0,0 +1,81 @@
+package connect
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConfigKeyError is a config key rejected by the validation of a plugin
+type ConfigKeyError struct {
+	Key     string
+	Message string
+}
+
+// ValidationError is returned by the pre-flight client when the config of a connector does not pass
+// the validation of its plugin, nothing is created or updated
+type ValidationError struct {
+	Connector string
+	Class     string
+	Errors    []ConfigKeyError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Key + ": " + err.Message
+	}
+	return fmt.Sprintf("validation error: connector %v has %d invalid configs: %v",
+		e.Connector, len(e.Errors), strings.Join(messages, "; "))
+}
+
+// preflightConnect validates the configs before they are sent to connect
+type preflightConnect struct {
+	Connect
+}
+
+// WithPreflight returns a client that validates the config of the requests passed to CreateConnector
+// and UpdateConnectorConfig with ValidatePluginConfig for their connector.class first,
+// returning a *ValidationError instead of sending a config with errors
+func WithPreflight(client Connect) Connect {
+	return &preflightConnect{Connect: client}
+}
+
+func (c *preflightConnect) CreateConnector(req ConnectorRequest) (*ConnectorResponse, error) {
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+	return c.Connect.CreateConnector(req)
+}
+
+func (c *preflightConnect) UpdateConnectorConfig(req ConnectorRequest) (*ConnectorResponse, error) {
+	if err := c.validate(req); err != nil {
+		return nil, err
+	}
+	return c.Connect.UpdateConnectorConfig(req)
+}
+
+func (c *preflightConnect) validate(req ConnectorRequest) error {
+	class := NormalizeConfigValue(req.Config["connector.class"])
+	if class == "" {
+		return &ValidationError{Connector: req.Name, Errors: []ConfigKeyError{{Key: "connector.class", Message: "Missing required configuration \"connector.class\""}}}
+	}
+	resp, err := c.Connect.ValidatePluginConfig(class, req)
+	if err != nil {
+		return err
+	}
+	if resp.ErrorCount == 0 {
+		return nil
+	}
+	validationErr := &ValidationError{Connector: req.Name, Class: class}
+	for _, config := range resp.ConfigInfos {
+		key, _ := config.Value["name"].(string)
+		messages, _ := config.Value["errors"].([]interface{})
+		for _, message := range messages {
+			validationErr.Errors = append(validationErr.Errors, ConfigKeyError{Key: key, Message: fmt.Sprint(message)})
+		}
+	}
+	if len(validationErr.Errors) == 0 {
+		validationErr.Errors = []ConfigKeyError{{Key: "connector.class", Message: fmt.Sprintf("%d validation errors", resp.ErrorCount)}}
+	}
+	return validationErr
+}
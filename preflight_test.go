@@ -0,0 +1,50 @@
+package connect
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// rejectingValidation is a cluster whose plugins require a topics config
+type rejectingValidation struct {
+	*fakeConnect
+}
+
+func (f rejectingValidation) ValidatePluginConfig(pluginName string, req ConnectorRequest) (*ValidateConnectorPluginResponse, error) {
+	f.record("validate " + pluginName)
+	response := &ValidateConnectorPluginResponse{Code: 200, Name: pluginName}
+	if _, ok := req.Config["topics"]; !ok {
+		response.ErrorCount = 1
+		response.ConfigInfos = []Config{
+			{Value: map[string]interface{}{"name": "connector.class", "errors": []interface{}{}}},
+			{Value: map[string]interface{}{"name": "topics", "errors": []interface{}{"Must configure one of topics or topics.regex"}}},
+		}
+	}
+	return response, nil
+}
+
+func TestWithPreflight(t *testing.T) {
+	fake := newFakeConnect()
+	client := WithPreflight(rejectingValidation{fake})
+
+	_, err := client.CreateConnector(ConnectorRequest{Name: "orders", Config: map[string]interface{}{"connector.class": "FileStreamSink"}})
+	validationErr, ok := errors.Cause(err).(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, &ValidationError{
+		Connector: "orders",
+		Class:     "FileStreamSink",
+		Errors:    []ConfigKeyError{{Key: "topics", Message: "Must configure one of topics or topics.regex"}},
+	}, validationErr)
+	assert.EqualError(t, err, "validation error: connector orders has 1 invalid configs: topics: Must configure one of topics or topics.regex")
+	assert.Equal(t, []string{"validate FileStreamSink"}, fake.calls)
+
+	_, err = client.UpdateConnectorConfig(ConnectorRequest{Name: "orders", Config: map[string]interface{}{}})
+	assert.EqualError(t, err, `validation error: connector orders has 1 invalid configs: connector.class: Missing required configuration "connector.class"`)
+
+	fake.calls = nil
+	_, err = client.CreateConnector(ConnectorRequest{Name: "orders", Config: map[string]interface{}{"connector.class": "FileStreamSink", "topics": "orders"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"validate FileStreamSink", "create orders"}, fake.calls)
+}
@@ -0,0 +1,300 @@
+package connect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+)
+
+// ReconcileAction is the change the reconciler makes to a single connector
+type ReconcileAction string
+
+const (
+	// ReconcileCreate creates a connector that does not exist on the cluster
+	ReconcileCreate ReconcileAction = "create"
+	// ReconcileUpdate updates the config of a connector that has drifted
+	ReconcileUpdate ReconcileAction = "update"
+	// ReconcileDelete deletes a connector that is not in the desired set
+	ReconcileDelete ReconcileAction = "delete"
+	// ReconcileNoop leaves a connector that already matches the desired config untouched
+	ReconcileNoop ReconcileAction = "unchanged"
+)
+
+// reconcileOrder is the order in which the plan is applied.
+// Drifted connectors are fixed first, new ones are created next and unmanaged ones are removed last
+var reconcileOrder = []ReconcileAction{ReconcileUpdate, ReconcileCreate, ReconcileDelete}
+
+// ReconcileStep is a single planned change
+type ReconcileStep struct {
+	Action  ReconcileAction  `json:"action"`
+	Name    string           `json:"name"`
+	Request ConnectorRequest `json:"-"`
+	// Changes are the config keys that are added, changed or removed by the step
+	Changes []ConfigChange `json:"changes,omitempty"`
+}
+
+// ReconcilePlan is the set of changes needed to converge the cluster to the desired connectors
+type ReconcilePlan struct {
+	Steps []ReconcileStep `json:"steps"`
+}
+
+// Changes returns the steps that modify the cluster
+func (p *ReconcilePlan) Changes() []ReconcileStep {
+	var steps []ReconcileStep
+	for _, step := range p.Steps {
+		if step.Action != ReconcileNoop {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// Count returns the number of steps with the given action
+func (p *ReconcilePlan) Count(action ReconcileAction) int {
+	count := 0
+	for _, step := range p.Steps {
+		if step.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// String renders the plan as a human readable diff
+//
+//	~ connector drifted-connector
+//	    ~ tasks.max: "1" => "10"
+//	+ connector new-connector
+//	    + connector.class = "io.confluent.connect.replicator.ReplicatorSourceConnector"
+//	- connector unmanaged-connector
+//	Plan: 1 to create, 1 to update, 1 to delete, 0 unchanged.
+func (p *ReconcilePlan) String() string {
+	var buf bytes.Buffer
+	for _, step := range p.Steps {
+		switch step.Action {
+		case ReconcileCreate:
+			fmt.Fprintf(&buf, "+ connector %v\n", step.Name)
+		case ReconcileUpdate:
+			fmt.Fprintf(&buf, "~ connector %v\n", step.Name)
+		case ReconcileDelete:
+			fmt.Fprintf(&buf, "- connector %v\n", step.Name)
+		default:
+			continue
+		}
+		for _, change := range step.Changes {
+			fmt.Fprintf(&buf, "    %v\n", change)
+		}
+	}
+	fmt.Fprintf(&buf, "Plan: %d to create, %d to update, %d to delete, %d unchanged.\n",
+		p.Count(ReconcileCreate), p.Count(ReconcileUpdate), p.Count(ReconcileDelete), p.Count(ReconcileNoop))
+	return buf.String()
+}
+
+// JSON renders the plan as indented JSON
+func (p *ReconcilePlan) JSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// ReconcileResult is the outcome of applying a single step
+type ReconcileResult struct {
+	Action ReconcileAction
+	Name   string
+	Err    error
+}
+
+// ReconcileReport describes what the reconciler changed
+type ReconcileReport struct {
+	Results []ReconcileResult
+}
+
+// Names returns the connectors that were successfully handled with the given action
+func (r *ReconcileReport) Names(action ReconcileAction) []string {
+	var names []string
+	for _, result := range r.Results {
+		if result.Action == action && result.Err == nil {
+			names = append(names, result.Name)
+		}
+	}
+	return names
+}
+
+// Failed returns the results of the steps that could not be applied
+func (r *ReconcileReport) Failed() []ReconcileResult {
+	var failed []ReconcileResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err returns an error summarising the failed steps, or nil if every step succeeded
+func (r *ReconcileReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.Errorf("reconcile error: %d of %d steps failed, first error on %v: %v",
+		len(failed), len(r.Results), failed[0].Name, failed[0].Err)
+}
+
+// Reconciler converges a connect cluster to a desired set of connectors
+type Reconciler struct {
+	client Connect
+
+	// Prune deletes connectors on the cluster that are not in the desired set
+	Prune bool
+	// Concurrency is the maximum number of connectors changed at the same time
+	Concurrency int
+}
+
+// NewReconciler creates a reconciler for the given connect client
+func NewReconciler(client Connect) *Reconciler {
+	return &Reconciler{
+		client:      client,
+		Concurrency: 4,
+	}
+}
+
+// Reconcile plans and applies the changes needed for the cluster to match the desired connectors
+func (r *Reconciler) Reconcile(desired []ConnectorRequest) (*ReconcileReport, error) {
+	plan, err := r.Plan(desired)
+	if err != nil {
+		return nil, err
+	}
+	report := r.Apply(plan)
+	return report, report.Err()
+}
+
+// Plan compares the desired connectors with the cluster and returns the changes needed to converge them.
+// Nothing is changed on the cluster, so the plan can be reviewed before it is applied
+func (r *Reconciler) Plan(desired []ConnectorRequest) (*ReconcilePlan, error) {
+	wanted := make(map[string]ConnectorRequest, len(desired))
+	for _, req := range desired {
+		if req.Name == "" {
+			return nil, errors.New("reconcile error: connector request without a name")
+		}
+		if _, ok := wanted[req.Name]; ok {
+			return nil, errors.Errorf("reconcile error: connector %v is defined more than once", req.Name)
+		}
+		wanted[req.Name] = req
+	}
+
+	existing, err := r.client.GetConnectors()
+	if err != nil {
+		return nil, errors.Wrap(err, "reconcile error: could not list connectors")
+	}
+	current := make(map[string]bool, len(existing.Connectors))
+	for _, name := range existing.Connectors {
+		current[name] = true
+	}
+
+	plan := new(ReconcilePlan)
+	for _, req := range desired {
+		if !current[req.Name] {
+			plan.Steps = append(plan.Steps, ReconcileStep{
+				Action:  ReconcileCreate,
+				Name:    req.Name,
+				Request: req,
+				Changes: DiffConfig(req.Config, nil),
+			})
+			continue
+		}
+
+		config, err := r.client.GetConnectorConfig(req.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reconcile error: could not get config for %v", req.Name)
+		}
+		changes := DiffConfig(req.Config, config.Config)
+		action := ReconcileUpdate
+		if len(changes) == 0 {
+			action = ReconcileNoop
+		}
+		plan.Steps = append(plan.Steps, ReconcileStep{Action: action, Name: req.Name, Request: req, Changes: changes})
+	}
+
+	if r.Prune {
+		var unmanaged []string
+		for name := range current {
+			if _, ok := wanted[name]; !ok {
+				unmanaged = append(unmanaged, name)
+			}
+		}
+		sort.Strings(unmanaged)
+		for _, name := range unmanaged {
+			plan.Steps = append(plan.Steps, ReconcileStep{Action: ReconcileDelete, Name: name})
+		}
+	}
+	return plan, nil
+}
+
+// Apply executes the plan, one action at a time, with at most Concurrency connectors changed in parallel.
+// Failures are recorded in the report and do not stop the remaining steps
+func (r *Reconciler) Apply(plan *ReconcilePlan) *ReconcileReport {
+	report := new(ReconcileReport)
+	for _, step := range plan.Steps {
+		if step.Action == ReconcileNoop {
+			report.Results = append(report.Results, ReconcileResult{Action: step.Action, Name: step.Name})
+		}
+	}
+
+	for _, action := range reconcileOrder {
+		var steps []ReconcileStep
+		for _, step := range plan.Steps {
+			if step.Action == action {
+				steps = append(steps, step)
+			}
+		}
+		report.Results = append(report.Results, r.applySteps(steps)...)
+	}
+	return report
+}
+
+// applySteps runs the steps on a bounded pool of workers, keeping the results in the order of the steps
+func (r *Reconciler) applySteps(steps []ReconcileStep) []ReconcileResult {
+	results := make([]ReconcileResult, len(steps))
+	concurrency := r.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, step := range steps {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, step ReconcileStep) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = ReconcileResult{Action: step.Action, Name: step.Name, Err: r.applyStep(step)}
+		}(i, step)
+	}
+	wg.Wait()
+	return results
+}
+
+func (r *Reconciler) applyStep(step ReconcileStep) error {
+	var err error
+	switch step.Action {
+	case ReconcileCreate:
+		_, err = r.client.CreateConnector(r.client.CreateConnectorRequest(step.Request))
+	case ReconcileUpdate:
+		_, err = r.client.UpdateConnectorConfig(step.Request)
+	case ReconcileDelete:
+		_, err = r.client.DeleteConnector(step.Name)
+	default:
+		err = errors.Errorf("unknown reconcile action %v", step.Action)
+	}
+	if err != nil {
+		logger.WithError(err).Errorf("Could not %v connector %v", step.Action, step.Name)
+		return err
+	}
+	logger.Infof("Reconciled connector %v: %v", step.Name, step.Action)
+	return nil
+}
@@ -0,0 +1,68 @@
+package connect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconciler_Plan(t *testing.T) {
+	fake := newFakeConnect()
+	fake.add("unchanged", map[string]interface{}{"connector.class": "A", "tasks.max": 1})
+	fake.add("drifted", map[string]interface{}{"connector.class": "A", "tasks.max": 1})
+	fake.add("unmanaged", map[string]interface{}{"connector.class": "A"})
+
+	reconciler := NewReconciler(fake)
+	reconciler.Prune = true
+	plan, err := reconciler.Plan([]ConnectorRequest{
+		{Name: "unchanged", Config: map[string]interface{}{"connector.class": "A", "tasks.max": 1}},
+		{Name: "drifted", Config: map[string]interface{}{"connector.class": "A", "tasks.max": 2}},
+		{Name: "new", Config: map[string]interface{}{"connector.class": "A"}},
+	})
+	assert.NoError(t, err)
+
+	actions := map[string]ReconcileAction{}
+	for _, step := range plan.Steps {
+		actions[step.Name] = step.Action
+	}
+	assert.Equal(t, map[string]ReconcileAction{
+		"unchanged": ReconcileNoop,
+		"drifted":   ReconcileUpdate,
+		"new":       ReconcileCreate,
+		"unmanaged": ReconcileDelete,
+	}, actions)
+	assert.Len(t, plan.Changes(), 3)
+}
+
+func TestReconciler_PlanRejectsDuplicates(t *testing.T) {
+	reconciler := NewReconciler(newFakeConnect())
+	_, err := reconciler.Plan([]ConnectorRequest{{Name: "a"}, {Name: "a"}})
+	assert.Error(t, err)
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	fake := newFakeConnect()
+	fake.add("drifted", map[string]interface{}{"connector.class": "A", "tasks.max": 1})
+	fake.add("unmanaged", map[string]interface{}{"connector.class": "A"})
+
+	reconciler := NewReconciler(fake)
+	reconciler.Concurrency = 1
+	report, err := reconciler.Reconcile([]ConnectorRequest{
+		{Name: "drifted", Config: map[string]interface{}{"connector.class": "A", "tasks.max": 2}},
+		{Name: "new", Config: map[string]interface{}{"connector.class": "A"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"update drifted", "create new"}, fake.calls)
+	assert.Equal(t, []string{"new"}, report.Names(ReconcileCreate))
+	assert.Equal(t, []string{"drifted"}, report.Names(ReconcileUpdate))
+	assert.Empty(t, report.Failed())
+
+	// a second run has nothing to do
+	report, err = reconciler.Reconcile([]ConnectorRequest{
+		{Name: "drifted", Config: map[string]interface{}{"connector.class": "A", "tasks.max": 2}},
+		{Name: "new", Config: map[string]interface{}{"connector.class": "A"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"drifted", "new"}, report.Names(ReconcileNoop))
+	assert.Len(t, fake.calls, 2)
+}
@@ -0,0 +1,183 @@
+package connect
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces sensitive config values, the same placeholder connect uses for PASSWORD configs
+const RedactedValue = "[hidden]"
+
+// DefaultRedactPatterns are the key patterns treated as sensitive by default
+var DefaultRedactPatterns = []string{"password", "secret", "sasl.jaas.config", "key", "token"}
+
+// DefaultRedactor masks the sensitive values in the log lines, errors, String methods and diffs of this package
+var DefaultRedactor = NewRedactor(DefaultRedactPatterns...)
+
+// keyValuePattern finds key=value and key: value pairs in plain text
+var keyValuePattern = regexp.MustCompile(`([A-Za-z0-9_.\-]+)(\s*[=:]\s*)("[^"]*"|[^\s,;}]+)`)
+
+// Redactor decides which config values are sensitive and masks them
+type Redactor struct {
+	mu           sync.RWMutex
+	patterns     []string
+	passwordKeys map[string]bool
+}
+
+// NewRedactor creates a redactor for the given key patterns.
+// A pattern matches the end of a key on a segment boundary, ignoring case, so the pattern password
+// matches connection.password and ssl_key_password, and the pattern key matches aws.secret.access.key
+// but not key.converter
+func NewRedactor(patterns ...string) *Redactor {
+	r := &Redactor{passwordKeys: map[string]bool{}}
+	r.SetPatterns(patterns...)
+	return r
+}
+
+// SetPatterns replaces the key patterns of the redactor
+func (r *Redactor) SetPatterns(patterns ...string) {
+	lower := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		lower[i] = strings.ToLower(pattern)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.patterns = lower
+}
+
+// AddPasswordKeys marks keys as sensitive whatever their name
+func (r *Redactor) AddPasswordKeys(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		r.passwordKeys[key] = true
+	}
+}
+
+// LearnPluginConfig marks the keys of a plugin that are typed PASSWORD in its config definitions
+func (r *Redactor) LearnPluginConfig(resp *ValidateConnectorPluginResponse) {
+	if resp == nil {
+		return
+	}
+	var keys []string
+	for _, config := range resp.ConfigInfos {
+		if configType, _ := config.Definition["type"].(string); configType == "PASSWORD" {
+			if name, _ := config.Definition["name"].(string); name != "" {
+				keys = append(keys, name)
+			}
+		}
+	}
+	r.AddPasswordKeys(keys...)
+}
+
+// IsSensitive reports whether the value of the config key must be masked
+func (r *Redactor) IsSensitive(key string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.passwordKeys[key] {
+		return true
+	}
+	key = strings.ToLower(key)
+	for _, pattern := range r.patterns {
+		if key == pattern {
+			return true
+		}
+		for _, separator := range []string{".", "_", "-"} {
+			if strings.HasSuffix(key, separator+pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RedactConfig returns a copy of the config with the sensitive values masked
+func (r *Redactor) RedactConfig(config map[string]interface{}) map[string]interface{} {
+	if config == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		redacted[key] = r.redactValue(key, value)
+	}
+	return redacted
+}
+
+// RedactString masks the sensitive values in a JSON document, such as a response body,
+// or in the key=value pairs of plain text
+func (r *Redactor) RedactString(s string) string {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(s), &doc); err == nil {
+		switch doc.(type) {
+		case map[string]interface{}, []interface{}:
+			if redacted, err := json.Marshal(r.redactValue("", doc)); err == nil {
+				return string(redacted)
+			}
+		}
+	}
+
+	return r.redactText(s)
+}
+
+// redactText masks the values of the sensitive key=value pairs in plain text,
+// such as the config maps that connect prints in its error messages
+func (r *Redactor) redactText(s string) string {
+	return keyValuePattern.ReplaceAllStringFunc(s, func(match string) string {
+		parts := keyValuePattern.FindStringSubmatch(match)
+		if !r.IsSensitive(parts[1]) {
+			return match
+		}
+		return parts[1] + parts[2] + RedactedValue
+	})
+}
+
+// redactValue masks the value if its key is sensitive, walking into nested objects and lists
+func (r *Redactor) redactValue(key string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if key != "" && r.IsSensitive(key) {
+		return RedactedValue
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for nestedKey, nestedValue := range v {
+			redacted[nestedKey] = r.redactValue(nestedKey, nestedValue)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue("", item)
+		}
+		return redacted
+	case string:
+		return r.redactText(v)
+	default:
+		return value
+	}
+}
+
+// String returns the request with its sensitive values masked
+func (r ConnectorRequest) String() string {
+	return fmt.Sprintf("{Name:%v Config:%v}", r.Name, DefaultRedactor.RedactConfig(r.Config))
+}
+
+// String returns the response with its sensitive values masked
+func (r ConnectorResponse) String() string {
+	return fmt.Sprintf("{Code:%v Name:%v Config:%v Tasks:%v}", r.Code, r.Name, DefaultRedactor.RedactConfig(r.Config), r.Tasks)
+}
+
+// String returns the response with its sensitive values masked
+func (r GetConnectorConfigResponse) String() string {
+	return fmt.Sprintf("{Code:%v Config:%v}", r.Code, DefaultRedactor.RedactConfig(r.Config))
+}
+
+// String returns the task details with its sensitive values masked
+func (t TaskDetails) String() string {
+	return fmt.Sprintf("{ID:%v Config:%v}", t.ID, DefaultRedactor.RedactConfig(t.Config))
+}
@@ -0,0 +1,47 @@
+package connect
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_IsSensitive(t *testing.T) {
+	redactor := NewRedactor(DefaultRedactPatterns...)
+	for _, key := range []string{"connection.password", "database_password", "sasl.jaas.config", "aws.secret.access.key", "client.secret", "api-token"} {
+		assert.True(t, redactor.IsSensitive(key), key)
+	}
+	for _, key := range []string{"key.converter", "aws.access.key.id", "topic.whitelist", "tasks.max", "key.ignore"} {
+		assert.False(t, redactor.IsSensitive(key), key)
+	}
+
+	redactor.LearnPluginConfig(&ValidateConnectorPluginResponse{ConfigInfos: []Config{
+		{Definition: map[string]interface{}{"name": "hec.auth", "type": "PASSWORD"}},
+		{Definition: map[string]interface{}{"name": "hec.url", "type": "STRING"}},
+	}})
+	assert.True(t, redactor.IsSensitive("hec.auth"))
+	assert.False(t, redactor.IsSensitive("hec.url"))
+}
+
+func TestRedactor_RedactString(t *testing.T) {
+	redactor := NewRedactor(DefaultRedactPatterns...)
+
+	body := `{"name":"db","config":{"connection.password":"hunter2","connection.user":"connect"},"tasks":[]}`
+	assert.Equal(t, `{"config":{"connection.password":"[hidden]","connection.user":"connect"},"name":"db","tasks":[]}`,
+		redactor.RedactString(body))
+
+	message := `{"error_code":400,"message":"Connector config {connection.password=hunter2, connection.user=connect} contains no connector type"}`
+	assert.Equal(t, `{"error_code":400,"message":"Connector config {connection.password=[hidden], connection.user=connect} contains no connector type"}`,
+		redactor.RedactString(message))
+
+	assert.Equal(t, "login failed for user=connect password=[hidden]", redactor.RedactString("login failed for user=connect password=hunter2"))
+}
+
+func TestConnectorRequest_String(t *testing.T) {
+	req := ConnectorRequest{Name: "db", Config: map[string]interface{}{
+		"sasl.jaas.config": `org.apache.kafka.common.security.plain.PlainLoginModule required username="u" password="p";`,
+	}}
+	assert.Equal(t, "{Name:db Config:map[sasl.jaas.config:[hidden]]}", fmt.Sprint(req))
+	assert.Equal(t, "{Name:db Config:map[sasl.jaas.config:[hidden]]}", fmt.Sprintf("%v", &req))
+}
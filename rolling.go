@@ -0,0 +1,288 @@
+package connect
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+)
+
+// RollingRestartResult is the outcome of restarting a single connector
+type RollingRestartResult struct {
+	Name string
+	// Batch is the index of the batch the connector was restarted in, starting at 0
+	Batch int
+	// Skipped is the reason the connector was not restarted, e.g. it is paused
+	Skipped string
+	Err     error
+}
+
+// RollingRestartReport is the outcome of a rolling restart
+type RollingRestartReport struct {
+	Results []RollingRestartResult
+	// Halted is set when the restart stopped because the failure rate exceeded the threshold
+	Halted bool
+	// Remaining are the connectors that were not restarted because the restart halted
+	Remaining []string
+}
+
+// Failed returns the results of the connectors that did not come back healthy
+func (r *RollingRestartReport) Failed() []RollingRestartResult {
+	var failed []RollingRestartResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// FailureRate returns the share of the restarted connectors that did not come back healthy
+func (r *RollingRestartReport) FailureRate() float64 {
+	restarted := 0
+	for _, result := range r.Results {
+		if result.Skipped == "" {
+			restarted++
+		}
+	}
+	if restarted == 0 {
+		return 0
+	}
+	return float64(len(r.Failed())) / float64(restarted)
+}
+
+// String renders the report one connector per line
+func (r *RollingRestartReport) String() string {
+	var buf bytes.Buffer
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(&buf, "batch %d: failed %v: %v\n", result.Batch, result.Name, result.Err)
+		case result.Skipped != "":
+			fmt.Fprintf(&buf, "batch %d: skipped %v: %v\n", result.Batch, result.Name, result.Skipped)
+		default:
+			fmt.Fprintf(&buf, "batch %d: restarted %v\n", result.Batch, result.Name)
+		}
+	}
+	if r.Halted {
+		fmt.Fprintf(&buf, "halted, %d connectors not restarted: %v\n", len(r.Remaining), strings.Join(r.Remaining, ", "))
+	}
+	return buf.String()
+}
+
+// Err returns an error when the restart halted or a connector did not come back healthy
+func (r *RollingRestartReport) Err() error {
+	failed := r.Failed()
+	if r.Halted {
+		return errors.Errorf("rolling restart error: halted after %d of %d connectors failed, %d not restarted, first error on %v: %v",
+			len(failed), len(r.Results), len(r.Remaining), failed[0].Name, failed[0].Err)
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("rolling restart error: %d of %d connectors failed, first error on %v: %v",
+			len(failed), len(r.Results), failed[0].Name, failed[0].Err)
+	}
+	return nil
+}
+
+// RollingRestart restarts connectors in batches, waiting for every batch to be RUNNING again before the next one.
+// Connectors are restarted with their tasks, which is asynchronous: the instances the restart response lists as
+// RESTARTING, or that a status check saw RESTARTING or UNASSIGNED, are RUNNING once their status says so.
+// The others, e.g. on workers that answer the restart without a status, only count as RUNNING after RestartGrace,
+// until then their status may be the one before the restart
+type RollingRestart struct {
+	client Connect
+
+	// BatchSize is the number of connectors restarted at the same time
+	BatchSize int
+	// Tasks restarts the tasks of the connectors one by one with RestartConnectorTask instead of restarting the connectors.
+	// A task restart returns once the task restarted, so the tasks only have to be RUNNING afterwards
+	Tasks bool
+	// HealthTimeout is how long a batch has to return to RUNNING
+	HealthTimeout time.Duration
+	// PollInterval is the time between the restart and the first status check, and between two checks
+	PollInterval time.Duration
+	// RestartGrace is how long the status of an instance whose restart was not seen may be the one before the restart
+	RestartGrace time.Duration
+	// BatchDelay is the time to wait between two batches, giving the cluster time to settle
+	BatchDelay time.Duration
+	// MaxFailureRate is the share of failed connectors above which the restart halts, zero halts on the first failure
+	MaxFailureRate float64
+}
+
+// NewRollingRestart creates a rolling restart of one connector at a time
+func NewRollingRestart(client Connect) *RollingRestart {
+	return &RollingRestart{
+		client:        client,
+		BatchSize:     1,
+		HealthTimeout: 2 * time.Minute,
+		PollInterval:  2 * time.Second,
+		RestartGrace:  10 * time.Second,
+	}
+}
+
+// Run restarts the connectors matching the selector
+func (r *RollingRestart) Run(selector Selector) (*RollingRestartReport, error) {
+	names, err := NewBulk(r.client).Select(selector)
+	if err != nil {
+		return nil, err
+	}
+	return r.RunNames(names), nil
+}
+
+// RunNames restarts the named connectors in order. Connectors that are paused or stopped are skipped
+func (r *RollingRestart) RunNames(names []string) *RollingRestartReport {
+	batchSize := r.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	report := &RollingRestartReport{}
+	for start, batch := 0, 0; start < len(names); start, batch = start+batchSize, batch+1 {
+		if batch > 0 && r.BatchDelay > 0 {
+			time.Sleep(r.BatchDelay)
+		}
+		end := start + batchSize
+		if end > len(names) {
+			end = len(names)
+		}
+
+		results := make([]RollingRestartResult, end-start)
+		var wg sync.WaitGroup
+		for i, name := range names[start:end] {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				results[i] = r.restart(name, batch)
+			}(i, name)
+		}
+		wg.Wait()
+		report.Results = append(report.Results, results...)
+
+		if len(report.Failed()) > 0 && report.FailureRate() > r.MaxFailureRate && end < len(names) {
+			report.Halted = true
+			report.Remaining = append([]string{}, names[end:]...)
+			logger.Errorf("Rolling restart halted, failure rate %.2f exceeds %.2f", report.FailureRate(), r.MaxFailureRate)
+			break
+		}
+	}
+	return report
+}
+
+// restart restarts a single connector and waits for it to be healthy
+func (r *RollingRestart) restart(name string, batch int) RollingRestartResult {
+	result := RollingRestartResult{Name: name, Batch: batch}
+	status, err := r.client.GetConnectorStatus(name)
+	if err != nil {
+		result.Err = errors.Wrap(err, "could not get status")
+		return result
+	}
+	switch state := status.ConnectorStatus["state"]; state {
+	case "PAUSED", "STOPPED":
+		result.Skipped = "connector is " + strings.ToLower(state)
+		return result
+	}
+
+	// the instances known to have restarted, -1 is the connector
+	var restarted map[int]bool
+	if r.Tasks {
+		// a task restart returns once the task restarted
+		restarted = map[int]bool{-1: true}
+		for _, task := range status.TasksStatus {
+			if _, err := r.client.RestartConnectorTask(name, task.ID); err != nil {
+				result.Err = errors.Wrapf(err, "could not restart task %d", task.ID)
+				return result
+			}
+			restarted[task.ID] = true
+		}
+	} else {
+		response, err := r.client.RestartConnectorWithOptions(name, RestartOptions{IncludeTasks: true})
+		if err != nil {
+			result.Err = errors.Wrap(err, "could not restart")
+			return result
+		}
+		restarted = restartingInstances(response)
+	}
+
+	result.Err = r.waitRunning(name, restarted)
+	if result.Err != nil {
+		logger.WithError(result.Err).Errorf("Connector %v did not come back after restart", name)
+	} else {
+		logger.Infof("Restarted connector %v", name)
+	}
+	return result
+}
+
+// restartingInstances returns the instances the restart response lists as RESTARTING, -1 is the connector
+func restartingInstances(response *GetConnectorStatusResponse) map[int]bool {
+	restarted := map[int]bool{}
+	if response == nil {
+		return restarted
+	}
+	if response.ConnectorStatus["state"] == "RESTARTING" {
+		restarted[-1] = true
+	}
+	for _, task := range response.TasksStatus {
+		if task.State == "RESTARTING" {
+			restarted[task.ID] = true
+		}
+	}
+	return restarted
+}
+
+// waitRunning waits for the connector and all its tasks to be RUNNING after their restart.
+// Until RestartGrace has passed, the status of an instance that is not known to have restarted may be the one
+// before the restart, so it neither counts as RUNNING nor as FAILED
+func (r *RollingRestart) waitRunning(name string, restarted map[int]bool) error {
+	start := time.Now()
+	// check returns whether the instance is RUNNING after its restart, or an error if it FAILED after it
+	check := func(id int, state, trace string) (bool, error) {
+		settled := restarted[id] || time.Since(start) >= r.RestartGrace
+		switch state {
+		case "RESTARTING", "UNASSIGNED":
+			restarted[id] = true
+		case "RUNNING":
+			return settled, nil
+		case "FAILED":
+			if !settled {
+				return false, nil
+			}
+			if id < 0 {
+				return false, errors.Errorf("connector FAILED: %v", firstTraceLine(trace))
+			}
+			return false, errors.Errorf("task %d FAILED: %v", id, firstTraceLine(trace))
+		}
+		return false, nil
+	}
+
+	deadline := start.Add(r.HealthTimeout)
+	for {
+		time.Sleep(r.PollInterval)
+		status, err := r.client.GetConnectorStatus(name)
+		if err == nil {
+			running, checkErr := check(-1, status.ConnectorStatus["state"], status.ConnectorStatus["trace"])
+			if checkErr != nil {
+				return checkErr
+			}
+			for _, task := range status.TasksStatus {
+				taskRunning, checkErr := check(task.ID, task.State, task.Trace)
+				if checkErr != nil {
+					return checkErr
+				}
+				running = running && taskRunning
+			}
+			if running {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return errors.Wrapf(err, "not RUNNING within %v", r.HealthTimeout)
+			}
+			return errors.Errorf("not RUNNING within %v", r.HealthTimeout)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package connect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingConnector is a cluster where one connector keeps failing
+type failingConnector struct {
+	*fakeConnect
+	name string
+}
+
+func (f failingConnector) GetConnectorStatus(name string) (*GetConnectorStatusResponse, error) {
+	status, err := f.fakeConnect.GetConnectorStatus(name)
+	if err == nil && name == f.name {
+		status.ConnectorStatus["state"] = "FAILED"
+		status.ConnectorStatus["trace"] = "org.apache.kafka.connect.errors.ConnectException: boom\n\tat Worker.java"
+	}
+	return status, err
+}
+
+func newRollingCluster() *fakeConnect {
+	fake := newFakeConnect()
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		fake.add(name, map[string]interface{}{"connector.class": "FileStreamSource"})
+		fake.tasks[name] = []TaskStatus{{ID: 0, State: "RUNNING"}, {ID: 1, State: "RUNNING"}}
+	}
+	fake.PauseConnector("c")
+	fake.calls = nil
+	return fake
+}
+
+func TestRollingRestart(t *testing.T) {
+	fake := newRollingCluster()
+	rolling := NewRollingRestart(fake)
+	rolling.BatchSize = 2
+	rolling.PollInterval = time.Millisecond
+
+	report, err := rolling.Run(Selector{Names: []string{"a", "b", "c"}})
+	assert.NoError(t, err)
+	assert.NoError(t, report.Err())
+	assert.ElementsMatch(t, []string{"restart a includeTasks=true onlyFailed=false", "restart b includeTasks=true onlyFailed=false"}, fake.calls)
+	assert.Equal(t, "batch 0: restarted a\nbatch 0: restarted b\nbatch 1: skipped c: connector is paused\n", report.String())
+
+	fake.calls = nil
+	rolling.BatchSize = 1
+	rolling.Tasks = true
+	report = rolling.RunNames([]string{"d"})
+	assert.NoError(t, report.Err())
+	assert.Equal(t, []string{"restart d/0", "restart d/1"}, fake.calls)
+}
+
+func TestRollingRestart_Halt(t *testing.T) {
+	fake := newRollingCluster()
+	rolling := NewRollingRestart(failingConnector{fakeConnect: fake, name: "b"})
+	rolling.PollInterval = time.Millisecond
+	rolling.MaxFailureRate = 0.4
+
+	report := rolling.RunNames([]string{"a", "b", "d", "e"})
+	assert.True(t, report.Halted)
+	assert.Equal(t, []string{"d", "e"}, report.Remaining)
+	assert.Equal(t, []string{"restart a includeTasks=true onlyFailed=false", "restart b includeTasks=true onlyFailed=false"}, fake.calls)
+	assert.Equal(t, 0.5, report.FailureRate())
+	assert.EqualError(t, report.Err(), "rolling restart error: halted after 1 of 2 connectors failed, 2 not restarted, first error on b: connector FAILED: org.apache.kafka.connect.errors.ConnectException: boom")
+
+	fake.calls = nil
+	rolling.MaxFailureRate = 0.5
+	report = rolling.RunNames([]string{"a", "b", "d", "e"})
+	assert.False(t, report.Halted)
+	assert.Equal(t, 0.25, report.FailureRate())
+	assert.EqualError(t, report.Err(), "rolling restart error: 1 of 4 connectors failed, first error on b: connector FAILED: org.apache.kafka.connect.errors.ConnectException: boom")
+}
+
+// oldWorker answers the restarts with a 204 and no status, and keeps reporting the status from before
+// the restart for the given number of reads
+type oldWorker struct {
+	*fakeConnect
+	stale int
+}
+
+func (w *oldWorker) RestartConnectorWithOptions(name string, options RestartOptions) (*GetConnectorStatusResponse, error) {
+	if _, err := w.fakeConnect.RestartConnectorWithOptions(name, options); err != nil {
+		return nil, err
+	}
+	return &GetConnectorStatusResponse{EmptyResponse: EmptyResponse{Code: 204}}, nil
+}
+
+func (w *oldWorker) GetConnectorStatus(name string) (*GetConnectorStatusResponse, error) {
+	if w.stale > 0 {
+		w.stale--
+		return &GetConnectorStatusResponse{
+			Name:            name,
+			ConnectorStatus: map[string]string{"state": "FAILED", "trace": "stale"},
+			TasksStatus:     []TaskStatus{{ID: 0, State: "RUNNING"}, {ID: 1, State: "FAILED", Trace: "stale"}},
+		}, nil
+	}
+	return w.fakeConnect.GetConnectorStatus(name)
+}
+
+func TestRollingRestart_NoRestartingStatus(t *testing.T) {
+	// the fake never reports RESTARTING, the restart response tells the instances restarted
+	fake := newRollingCluster()
+	rolling := NewRollingRestart(fake)
+	rolling.PollInterval = time.Millisecond
+	rolling.RestartGrace = time.Hour
+	rolling.HealthTimeout = time.Second
+	started := time.Now()
+	report := rolling.RunNames([]string{"a", "b"})
+	assert.NoError(t, report.Err())
+	assert.Less(t, time.Since(started), time.Second)
+
+	// without a status in the response, RUNNING counts after the grace period
+	rolling = NewRollingRestart(&oldWorker{fakeConnect: fake})
+	rolling.PollInterval = time.Millisecond
+	rolling.RestartGrace = 20 * time.Millisecond
+	started = time.Now()
+	report = rolling.RunNames([]string{"a"})
+	assert.NoError(t, report.Err())
+	assert.GreaterOrEqual(t, time.Since(started), 20*time.Millisecond)
+}
+
+func TestRollingRestart_StaleStatus(t *testing.T) {
+	// the status from before the restart is not reported as failed during the grace period
+	fake := newRollingCluster()
+	rolling := NewRollingRestart(&oldWorker{fakeConnect: fake, stale: 3})
+	rolling.PollInterval = time.Millisecond
+	rolling.RestartGrace = 50 * time.Millisecond
+	report := rolling.RunNames([]string{"a"})
+	assert.NoError(t, report.Err())
+	assert.Equal(t, []string{"restart a includeTasks=true onlyFailed=false"}, fake.calls)
+
+	// a failure that outlasts it is
+	rolling = NewRollingRestart(&oldWorker{fakeConnect: fake, stale: 1 << 30})
+	rolling.PollInterval = time.Millisecond
+	rolling.RestartGrace = 10 * time.Millisecond
+	report = rolling.RunNames([]string{"b"})
+	assert.EqualError(t, report.Err(), "rolling restart error: 1 of 1 connectors failed, first error on b: connector FAILED: stale")
+}
@@ -5,6 +5,8 @@ package connect
 type ConnectorRequest struct {
 	Name   string                 `json:"name"`
 	Config map[string]interface{} `json:"config"`
+	// InitialState is the state the connector is created in: RUNNING, PAUSED or STOPPED. Requires kafka 3.5 or later
+	InitialState string `json:"initial_state,omitempty"`
 }
 
 //ConnectorResponse is the response returned from the connect endpoint
@@ -13,6 +15,7 @@ type ConnectorResponse struct {
 	Name   string                 `json:"name"`
 	Config map[string]interface{} `json:"config"`
 	Tasks  []TaskID               `json:"tasks"`
+	Type   string                 `json:"type,omitempty"`
 }
 
 // EmptyResponse is response returned by kafka connect endpoints that return only the status code
@@ -33,6 +36,7 @@ type GetConnectorStatusResponse struct {
 	Name            string            `json:"name"`
 	ConnectorStatus map[string]string `json:"connector"`
 	TasksStatus     []TaskStatus      `json:"tasks"`
+	Type            string            `json:"type,omitempty"`
 }
 
 type GetConnectorConfigResponse struct {
@@ -46,6 +50,18 @@ type GetAllConnectorsResponse struct {
 	Connectors []string
 }
 
+//GetExpandedConnectorsResponse is the status and info of all connectors, keyed by connector name
+type GetExpandedConnectorsResponse struct {
+	EmptyResponse
+	Connectors map[string]ExpandedConnector
+}
+
+//ExpandedConnector is the status and info of a connector returned by the expanded listing
+type ExpandedConnector struct {
+	Status GetConnectorStatusResponse `json:"status"`
+	Info   ConnectorResponse          `json:"info"`
+}
+
 type GetConnectorTasksResponse struct {
 	Code  int
 	Tasks []TaskDetails
@@ -73,11 +89,27 @@ type TaskStatus struct {
 	ID       int    `json:"id"`
 	State    string `json:"state"`
 	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"`
 }
 
 type ConnectorPluginsResponse struct {
-	Code  int
-	Class string `json:"class"`
+	Code int
+	// Deprecated: the endpoint returns a list of plugins, Class is the class of the first one. Use Plugins
+	Class   string `json:"class"`
+	Plugins []ConnectorPlugin
+}
+
+//ConnectorPlugin is a connector plugin installed on the worker
+type ConnectorPlugin struct {
+	Class   string `json:"class"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+}
+
+//RestartOptions selects which connector and task instances are restarted
+type RestartOptions struct {
+	IncludeTasks bool
+	OnlyFailed   bool
 }
 
 type ValidateConnectorPluginResponse struct {
@@ -85,10 +117,24 @@ type ValidateConnectorPluginResponse struct {
 	Name       string   `json:"name"`
 	ErrorCount int      `json:"error_count"`
 	Groups     []string `json:"groups"`
-	Configs    Config
+	// Deprecated: the endpoint returns a list of configs, which cannot be decoded in a single Config. Use ConfigInfos
+	Configs     Config   `json:"-"`
+	ConfigInfos []Config `json:"configs"`
 }
 
 type Config struct {
 	Definition map[string]interface{} `json:"definition"`
 	Value      map[string]interface{} `json:"value"`
 }
+
+//ConnectorOffset is the offset of a source partition, or of a topic partition for sink connectors
+type ConnectorOffset struct {
+	Partition map[string]interface{} `json:"partition" yaml:"partition"`
+	Offset    map[string]interface{} `json:"offset" yaml:"offset"`
+}
+
+//GetConnectorOffsetsResponse is the committed offsets of a connector, also the body used to alter them
+type GetConnectorOffsetsResponse struct {
+	Code    int               `json:"-"`
+	Offsets []ConnectorOffset `json:"offsets"`
+}
@@ -0,0 +1,323 @@
+package connect
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// template providers expanded on the client, env and file only when listed in Templater.ClientProviders
+const (
+	templateEnv  = "env"
+	templateFile = "file"
+	templateVar  = "var"
+)
+
+// TemplateError is a reference in a config value that could not be resolved
+type TemplateError struct {
+	Key       string
+	Reference string
+	Reason    string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("%v: could not resolve %v: %v", e.Key, e.Reference, e.Reason)
+}
+
+// TemplateErrors are all the unresolved references of a connector config
+type TemplateErrors []*TemplateError
+
+func (e TemplateErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "template error: " + strings.Join(messages, "; ")
+}
+
+// Templater expands references in connector config values before they are sent to connect.
+//
+//	${var:NAME}             the user supplied variable NAME
+//	${env:NAME}             the environment variable NAME, when env is in ClientProviders
+//	${file:path:key}        the key of a properties file, when file is in ClientProviders.
+//	                        Relative paths are resolved from BaseDir
+//	${var:NAME:-default}    any reference can have a default used when it does not resolve
+//	$${var:NAME}            escapes a reference, which is sent to connect as ${var:NAME}
+//
+// env and file are also the aliases of the FileConfigProvider and EnvVarConfigProvider of the workers,
+// so by default their references are left untouched for the worker to resolve, which keeps secrets out
+// of the config sent over REST. References to any other provider, such as ${vault:path:key}, and
+// placeholders without a provider, such as the ${topic} of the TimestampRouter, are left untouched too
+type Templater struct {
+	// Vars are the user supplied variables
+	Vars map[string]string
+	// ClientProviders are the providers among env and file expanded on the client instead of the worker
+	ClientProviders []string
+	// BaseDir is the directory relative file references are resolved from
+	BaseDir string
+	// LookupEnv looks up environment variables, os.LookupEnv by default
+	LookupEnv func(string) (string, bool)
+
+	mu    sync.Mutex
+	files map[string]map[string]string
+}
+
+// NewTemplater creates a templater with the given user variables
+func NewTemplater(vars map[string]string) *Templater {
+	return &Templater{
+		Vars:      vars,
+		LookupEnv: os.LookupEnv,
+	}
+}
+
+// ExpandRequest returns a copy of the request with the references in its config values expanded.
+// All the unresolved references are returned together as TemplateErrors
+func (t *Templater) ExpandRequest(req ConnectorRequest) (ConnectorRequest, error) {
+	expanded := req
+	expanded.Config = make(map[string]interface{}, len(req.Config))
+
+	keys := make([]string, 0, len(req.Config))
+	for key := range req.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs TemplateErrors
+	for _, key := range keys {
+		value, ok := req.Config[key].(string)
+		if !ok {
+			expanded.Config[key] = req.Config[key]
+			continue
+		}
+		result, keyErrs := t.expand(key, value)
+		errs = append(errs, keyErrs...)
+		expanded.Config[key] = result
+	}
+	if len(errs) > 0 {
+		return req, errs
+	}
+	return expanded, nil
+}
+
+// ExpandRequests expands all the requests, e.g. before they are planned by a Reconciler
+func (t *Templater) ExpandRequests(reqs []ConnectorRequest) ([]ConnectorRequest, error) {
+	expanded := make([]ConnectorRequest, len(reqs))
+	var errs TemplateErrors
+	for i, req := range reqs {
+		result, err := t.ExpandRequest(req)
+		if err != nil {
+			reqErrs, ok := err.(TemplateErrors)
+			if !ok {
+				return nil, err
+			}
+			for _, reqErr := range reqErrs {
+				reqErr.Key = req.Name + "/" + reqErr.Key
+			}
+			errs = append(errs, reqErrs...)
+		}
+		expanded[i] = result
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return expanded, nil
+}
+
+// Expand expands the references in a single value
+func (t *Templater) Expand(value string) (string, error) {
+	result, errs := t.expand("value", value)
+	if len(errs) > 0 {
+		return value, errs
+	}
+	return result, nil
+}
+
+func (t *Templater) expand(key, value string) (string, TemplateErrors) {
+	var buf bytes.Buffer
+	var errs TemplateErrors
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			buf.WriteString(value)
+			break
+		}
+		if start > 0 && value[start-1] == '$' {
+			// $${...} is an escaped reference
+			buf.WriteString(value[:start-1])
+			buf.WriteString("${")
+			value = value[start+2:]
+			continue
+		}
+		end := strings.IndexByte(value[start:], '}')
+		if end < 0 {
+			buf.WriteString(value)
+			break
+		}
+		end += start
+
+		reference := value[start : end+1]
+		buf.WriteString(value[:start])
+		resolved, handled, err := t.resolve(value[start+2 : end])
+		switch {
+		case !handled:
+			buf.WriteString(reference)
+		case err != nil:
+			errs = append(errs, &TemplateError{Key: key, Reference: reference, Reason: err.Error()})
+			buf.WriteString(reference)
+		default:
+			buf.WriteString(resolved)
+		}
+		value = value[end+1:]
+	}
+	return buf.String(), errs
+}
+
+// resolve resolves the content of a reference. handled is false for references left to the worker
+func (t *Templater) resolve(reference string) (value string, handled bool, err error) {
+	colon := strings.IndexByte(reference, ':')
+	if colon < 0 {
+		return "", false, nil
+	}
+	provider, rest := reference[:colon], reference[colon+1:]
+	if provider != templateVar && !t.expandsOnClient(provider) {
+		return "", false, nil
+	}
+
+	def, hasDefault := "", false
+	if i := strings.Index(rest, ":-"); i >= 0 {
+		rest, def, hasDefault = rest[:i], rest[i+2:], true
+	}
+
+	var ok bool
+	switch provider {
+	case templateEnv:
+		lookup := t.LookupEnv
+		if lookup == nil {
+			lookup = os.LookupEnv
+		}
+		value, ok = lookup(rest)
+		err = errors.Errorf("environment variable %v is not set", rest)
+	case templateVar:
+		value, ok = t.Vars[rest]
+		err = errors.Errorf("variable %v is not defined", rest)
+	case templateFile:
+		i := strings.LastIndexByte(rest, ':')
+		if i < 0 {
+			return "", true, errors.New("file references must be ${file:path:key}")
+		}
+		value, ok, err = t.lookupFile(rest[:i], rest[i+1:])
+		if err != nil {
+			if hasDefault {
+				return def, true, nil
+			}
+			return "", true, err
+		}
+		err = errors.Errorf("key %v is not defined in %v", rest[i+1:], rest[:i])
+	default:
+		return "", false, nil
+	}
+
+	if ok {
+		return value, true, nil
+	}
+	if hasDefault {
+		return def, true, nil
+	}
+	return "", true, err
+}
+
+func (t *Templater) expandsOnClient(provider string) bool {
+	for _, clientProvider := range t.ClientProviders {
+		if provider == clientProvider {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupFile reads a key from a properties file, caching the file for the next references
+func (t *Templater) lookupFile(path, key string) (string, bool, error) {
+	if !filepath.IsAbs(path) && t.BaseDir != "" {
+		path = filepath.Join(t.BaseDir, path)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.files == nil {
+		t.files = map[string]map[string]string{}
+	}
+	properties, ok := t.files[path]
+	if !ok {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", false, errors.Wrap(err, "could not read file")
+		}
+		properties = parseProperties(data)
+		t.files[path] = properties
+	}
+	value, ok := properties[key]
+	return value, ok, nil
+}
+
+// parseProperties parses the key=value and key: value lines of a properties file
+func parseProperties(data []byte) map[string]string {
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			properties[line] = ""
+			continue
+		}
+		properties[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+	}
+	return properties
+}
+
+// templatingConnect expands the requests before they are sent to connect
+type templatingConnect struct {
+	Connect
+	templater *Templater
+}
+
+// WithTemplating returns a client that expands the config of the requests passed to
+// CreateConnector, UpdateConnectorConfig and ValidatePluginConfig with the templater
+func WithTemplating(client Connect, templater *Templater) Connect {
+	return &templatingConnect{Connect: client, templater: templater}
+}
+
+func (c *templatingConnect) CreateConnector(req ConnectorRequest) (*ConnectorResponse, error) {
+	expanded, err := c.templater.ExpandRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect.CreateConnector(expanded)
+}
+
+func (c *templatingConnect) UpdateConnectorConfig(req ConnectorRequest) (*ConnectorResponse, error) {
+	expanded, err := c.templater.ExpandRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect.UpdateConnectorConfig(expanded)
+}
+
+func (c *templatingConnect) ValidatePluginConfig(pluginName string, req ConnectorRequest) (*ValidateConnectorPluginResponse, error) {
+	expanded, err := c.templater.ExpandRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect.ValidatePluginConfig(pluginName, expanded)
+}
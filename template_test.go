@@ -0,0 +1,96 @@
+package connect
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplater_ExpandRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "db.properties"), []byte("# database\nhost = db.internal\nport: 5432\n"), 0600))
+
+	templater := NewTemplater(map[string]string{"prefix": "staging"})
+	templater.ClientProviders = []string{"env", "file"}
+	templater.BaseDir = dir
+	templater.LookupEnv = func(name string) (string, bool) {
+		if name == "BOOTSTRAP_SERVERS" {
+			return "kafka:9092", true
+		}
+		return "", false
+	}
+
+	req, err := templater.ExpandRequest(ConnectorRequest{
+		Name: "users",
+		Config: map[string]interface{}{
+			"bootstrap.servers":     "${env:BOOTSTRAP_SERVERS}",
+			"database.url":          "jdbc:postgresql://${file:db.properties:host}:${file:db.properties:port}/users",
+			"topic.prefix":          "${var:prefix}.",
+			"database.user":         "${env:DB_USER:-connect}",
+			"database.password":     "${vault:secret/db:password}",
+			"topic.format":          "${topic}-${timestamp}",
+			"escaped":               "$${file:/etc/secrets.properties:token}",
+			"tasks.max":             1,
+			"transforms.route.type": "org.apache.kafka.connect.transforms.TimestampRouter",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"bootstrap.servers":     "kafka:9092",
+		"database.url":          "jdbc:postgresql://db.internal:5432/users",
+		"topic.prefix":          "staging.",
+		"database.user":         "connect",
+		"database.password":     "${vault:secret/db:password}",
+		"topic.format":          "${topic}-${timestamp}",
+		"escaped":               "${file:/etc/secrets.properties:token}",
+		"tasks.max":             1,
+		"transforms.route.type": "org.apache.kafka.connect.transforms.TimestampRouter",
+	}, req.Config)
+}
+
+func TestTemplater_Unresolved(t *testing.T) {
+	templater := NewTemplater(nil)
+	templater.ClientProviders = []string{"env"}
+	templater.LookupEnv = func(string) (string, bool) { return "", false }
+
+	req := ConnectorRequest{Name: "users", Config: map[string]interface{}{
+		"a": "${env:MISSING}",
+		"b": "${var:missing}",
+	}}
+	_, err := templater.ExpandRequest(req)
+	assert.EqualError(t, err, "template error: a: could not resolve ${env:MISSING}: environment variable MISSING is not set; "+
+		"b: could not resolve ${var:missing}: variable missing is not defined")
+}
+
+func TestTemplater_WorkerProviders(t *testing.T) {
+	templater := NewTemplater(map[string]string{"env": "staging"})
+	templater.LookupEnv = func(string) (string, bool) { return "client-secret", true }
+
+	value, err := templater.Expand("${file:/opt/secrets.properties:password} ${env:DB_PASSWORD} ${var:env}")
+	assert.NoError(t, err)
+	assert.Equal(t, "${file:/opt/secrets.properties:password} ${env:DB_PASSWORD} staging", value)
+}
+
+func TestWithTemplating(t *testing.T) {
+	fake := newFakeConnect()
+	client := WithTemplating(fake, NewTemplater(map[string]string{"class": "A"}))
+
+	_, err := client.CreateConnector(ConnectorRequest{Name: "a", Config: map[string]interface{}{"connector.class": "${var:class}"}})
+	assert.NoError(t, err)
+	config, err := client.GetConnectorConfig("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "A", config.Config["connector.class"])
+
+	_, err = client.UpdateConnectorConfig(ConnectorRequest{Name: "a", Config: map[string]interface{}{"connector.class": "${var:other}"}})
+	assert.Error(t, err)
+
+	_, err = client.CreateConnector(ConnectorRequest{Name: "b", InitialState: "PAUSED", Config: map[string]interface{}{"connector.class": "${var:class}"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "create b initial_state=PAUSED", fake.calls[len(fake.calls)-1])
+	assert.Equal(t, "PAUSED", fake.states["b"])
+}
@@ -0,0 +1,327 @@
+package connect
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	logger "github.com/sirupsen/logrus"
+)
+
+// WatchEventType is the kind of change reported by a Watcher
+type WatchEventType string
+
+const (
+	// ConnectorAdded is emitted for a connector that appeared, and for every connector on the first poll
+	ConnectorAdded WatchEventType = "ConnectorAdded"
+	// ConnectorRemoved is emitted for a connector that was deleted
+	ConnectorRemoved WatchEventType = "ConnectorRemoved"
+	// ConnectorStateChanged is emitted when the state of the connector instance changes
+	ConnectorStateChanged WatchEventType = "ConnectorStateChanged"
+	// TaskStateChanged is emitted when the state of a task changes, including tasks that appear or disappear
+	TaskStateChanged WatchEventType = "TaskStateChanged"
+	// TaskMovedWorker is emitted when a task is assigned to another worker
+	TaskMovedWorker WatchEventType = "TaskMovedWorker"
+	// ConnectorConfigChanged is emitted when the config of a connector changes
+	ConnectorConfigChanged WatchEventType = "ConnectorConfigChanged"
+	// ConnectorResync is emitted for every connector once per resync period with its current state
+	ConnectorResync WatchEventType = "ConnectorResync"
+)
+
+// WatchEvent is a change in the connectors of the cluster
+type WatchEvent struct {
+	Type      WatchEventType
+	Connector string
+	// Task is the id of the task for task events, -1 otherwise
+	Task      int
+	OldState  string
+	NewState  string
+	OldWorker string
+	NewWorker string
+	// Trace is the error trace of a FAILED connector or task
+	Trace string
+	// Changes are the config keys that changed, for ConnectorConfigChanged events
+	Changes []ConfigChange
+	Time    time.Time
+}
+
+// connectorSnapshot is the state of a connector seen by the last poll
+type connectorSnapshot struct {
+	state  string
+	worker string
+	trace  string
+	tasks  map[int]TaskStatus
+	config map[string]interface{}
+}
+
+// Watcher polls the connectors of a cluster and streams the changes between polls as events
+type Watcher struct {
+	client Connect
+
+	// Interval is the time between two polls
+	Interval time.Duration
+	// ResyncPeriod is the time between two ConnectorResync events for every connector, zero disables resyncs
+	ResyncPeriod time.Duration
+	// OnError is called when a poll fails, the failure is logged when it is nil
+	OnError func(error)
+
+	events   chan WatchEvent
+	stop     chan struct{}
+	done     chan struct{}
+	mu       sync.Mutex
+	started  bool
+	stopOnce sync.Once
+	// sendMu guards the sends on events against its closing, closed is set once it is closed
+	sendMu sync.Mutex
+	closed bool
+
+	snapshot   map[string]connectorSnapshot
+	lastResync time.Time
+	noExpand   bool
+}
+
+// NewWatcher creates a watcher polling the cluster every interval
+func NewWatcher(client Connect, interval time.Duration) *Watcher {
+	return &Watcher{
+		client:   client,
+		Interval: interval,
+		events:   make(chan WatchEvent, 100),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Events returns the channel the events are sent on. It is closed once the watcher is stopped
+func (w *Watcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Start polls the cluster in the background until Stop is called
+func (w *Watcher) Start() <-chan WatchEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.started {
+		w.started = true
+		go w.run()
+	}
+	return w.events
+}
+
+// Stop stops polling and waits for the running poll to finish before closing the events channel
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	started := w.started
+	w.started = true
+	w.mu.Unlock()
+
+	w.stopOnce.Do(func() {
+		close(w.stop)
+		if !started {
+			w.closeEvents()
+			close(w.done)
+		}
+	})
+	<-w.done
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+	defer w.closeEvents()
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Poll(); err != nil {
+			if w.OnError != nil {
+				w.OnError(err)
+			} else {
+				logger.WithError(err).Error("Could not poll connectors")
+			}
+		}
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// closeEvents closes the events channel once no poll is sending on it
+func (w *Watcher) closeEvents() {
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	w.closed = true
+	close(w.events)
+}
+
+// Poll takes a snapshot of the cluster and emits the changes since the previous one.
+// It is called by the background loop started with Start, and can be called directly instead of Start
+// to drive the watcher, as long as the events are consumed. Poll does nothing once Stop was called
+func (w *Watcher) Poll() error {
+	select {
+	case <-w.stop:
+		return nil
+	default:
+	}
+	current, err := w.list()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	resync := false
+	if w.snapshot != nil && w.ResyncPeriod > 0 && now.Sub(w.lastResync) >= w.ResyncPeriod {
+		resync = true
+	}
+	if w.snapshot == nil || resync {
+		w.lastResync = now
+	}
+
+	var events []WatchEvent
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		previous, existed := w.snapshot[name]
+		if !existed {
+			snapshot := current[name]
+			events = append(events, WatchEvent{Type: ConnectorAdded, Connector: name, Task: -1,
+				NewState: snapshot.state, NewWorker: snapshot.worker, Trace: snapshot.trace})
+			continue
+		}
+		events = append(events, diffSnapshots(name, previous, current[name], resync)...)
+	}
+
+	var removed []string
+	for name := range w.snapshot {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		previous := w.snapshot[name]
+		events = append(events, WatchEvent{Type: ConnectorRemoved, Connector: name, Task: -1,
+			OldState: previous.state, OldWorker: previous.worker})
+	}
+
+	w.snapshot = current
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	if w.closed {
+		return nil
+	}
+	for _, event := range events {
+		event.Time = now
+		select {
+		case w.events <- event:
+		case <-w.stop:
+			return nil
+		}
+	}
+	return nil
+}
+
+// diffSnapshots returns the events for the changes of a single connector
+func diffSnapshots(name string, previous, current connectorSnapshot, resync bool) []WatchEvent {
+	var events []WatchEvent
+	if resync {
+		events = append(events, WatchEvent{Type: ConnectorResync, Connector: name, Task: -1,
+			OldState: current.state, NewState: current.state, OldWorker: current.worker, NewWorker: current.worker, Trace: current.trace})
+	}
+	if previous.state != current.state {
+		events = append(events, WatchEvent{Type: ConnectorStateChanged, Connector: name, Task: -1,
+			OldState: previous.state, NewState: current.state, OldWorker: previous.worker, NewWorker: current.worker, Trace: current.trace})
+	}
+
+	ids := map[int]bool{}
+	for id := range previous.tasks {
+		ids[id] = true
+	}
+	for id := range current.tasks {
+		ids[id] = true
+	}
+	sorted := make([]int, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Ints(sorted)
+	for _, id := range sorted {
+		before, after := previous.tasks[id], current.tasks[id]
+		if before.State != after.State {
+			events = append(events, WatchEvent{Type: TaskStateChanged, Connector: name, Task: id,
+				OldState: before.State, NewState: after.State, OldWorker: before.WorkerID, NewWorker: after.WorkerID, Trace: after.Trace})
+		}
+		if before.WorkerID != "" && after.WorkerID != "" && before.WorkerID != after.WorkerID {
+			events = append(events, WatchEvent{Type: TaskMovedWorker, Connector: name, Task: id,
+				OldState: before.State, NewState: after.State, OldWorker: before.WorkerID, NewWorker: after.WorkerID})
+		}
+	}
+
+	if current.config != nil && previous.config != nil {
+		if changes := DiffConfig(current.config, previous.config); len(changes) > 0 {
+			events = append(events, WatchEvent{Type: ConnectorConfigChanged, Connector: name, Task: -1, Changes: changes})
+		}
+	}
+	return events
+}
+
+// list takes a snapshot of all connectors, using the expanded listing when the worker supports it
+func (w *Watcher) list() (map[string]connectorSnapshot, error) {
+	if !w.noExpand {
+		expanded, err := w.client.GetConnectorsExpanded()
+		if err == nil {
+			snapshots := make(map[string]connectorSnapshot, len(expanded.Connectors))
+			for name, connector := range expanded.Connectors {
+				snapshots[name] = newConnectorSnapshot(&connector.Status, connector.Info.Config)
+			}
+			return snapshots, nil
+		}
+		if err != ErrExpandNotSupported {
+			return nil, errors.Wrap(err, "watch error: could not list connectors")
+		}
+		w.noExpand = true
+	}
+
+	connectors, err := w.client.GetConnectors()
+	if err != nil {
+		return nil, errors.Wrap(err, "watch error: could not list connectors")
+	}
+	snapshots := make(map[string]connectorSnapshot, len(connectors.Connectors))
+	for _, name := range connectors.Connectors {
+		status, err := w.client.GetConnectorStatus(name)
+		if IsNotFound(err) {
+			// deleted since it was listed
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "watch error: could not get status of %v", name)
+		}
+		config, err := w.client.GetConnectorConfig(name)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "watch error: could not get config of %v", name)
+		}
+		snapshots[name] = newConnectorSnapshot(status, config.Config)
+	}
+	return snapshots, nil
+}
+
+func newConnectorSnapshot(status *GetConnectorStatusResponse, config map[string]interface{}) connectorSnapshot {
+	snapshot := connectorSnapshot{
+		state:  status.ConnectorStatus["state"],
+		worker: status.ConnectorStatus["worker_id"],
+		trace:  status.ConnectorStatus["trace"],
+		tasks:  make(map[int]TaskStatus, len(status.TasksStatus)),
+		config: config,
+	}
+	for _, task := range status.TasksStatus {
+		snapshot.tasks[task.ID] = task
+	}
+	return snapshot
+}
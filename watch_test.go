@@ -0,0 +1,96 @@
+package connect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// drain returns the events already sent by the watcher
+func drain(w *Watcher) []WatchEvent {
+	var events []WatchEvent
+	for {
+		select {
+		case event := <-w.Events():
+			event.Time = time.Time{}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	for _, noExpand := range []bool{false, true} {
+		fake := newFakeConnect()
+		fake.noExpand = noExpand
+		fake.add("a", map[string]interface{}{"tasks.max": 1})
+		fake.setTasks("a", TaskStatus{ID: 0, State: "RUNNING", WorkerID: "w1"}, TaskStatus{ID: 1, State: "RUNNING", WorkerID: "w1"})
+		fake.add("b", map[string]interface{}{"tasks.max": 1})
+
+		watcher := NewWatcher(fake, time.Minute)
+		assert.NoError(t, watcher.Poll())
+		assert.Equal(t, []WatchEvent{
+			{Type: ConnectorAdded, Connector: "a", Task: -1, NewState: "RUNNING", NewWorker: "worker-1:8083"},
+			{Type: ConnectorAdded, Connector: "b", Task: -1, NewState: "RUNNING", NewWorker: "worker-1:8083"},
+		}, drain(watcher))
+
+		// nothing changed
+		assert.NoError(t, watcher.Poll())
+		assert.Empty(t, drain(watcher))
+
+		fake.PauseConnector("a")
+		fake.setTasks("a", TaskStatus{ID: 0, State: "FAILED", WorkerID: "w1", Trace: "boom"}, TaskStatus{ID: 1, State: "RUNNING", WorkerID: "w2"})
+		fake.UpdateConnectorConfig(ConnectorRequest{Name: "a", Config: map[string]interface{}{"tasks.max": 2}})
+		fake.DeleteConnector("b")
+		assert.NoError(t, watcher.Poll())
+		assert.Equal(t, []WatchEvent{
+			{Type: ConnectorStateChanged, Connector: "a", Task: -1, OldState: "RUNNING", NewState: "PAUSED", OldWorker: "worker-1:8083", NewWorker: "worker-1:8083"},
+			{Type: TaskStateChanged, Connector: "a", Task: 0, OldState: "RUNNING", NewState: "FAILED", OldWorker: "w1", NewWorker: "w1", Trace: "boom"},
+			{Type: TaskMovedWorker, Connector: "a", Task: 1, OldState: "RUNNING", NewState: "RUNNING", OldWorker: "w1", NewWorker: "w2"},
+			{Type: ConnectorConfigChanged, Connector: "a", Task: -1, Changes: []ConfigChange{{Key: "tasks.max", Type: ConfigChanged, Old: "1", New: "2"}}},
+			{Type: ConnectorRemoved, Connector: "b", Task: -1, OldState: "RUNNING", OldWorker: "worker-1:8083"},
+		}, drain(watcher), "noExpand=%v", noExpand)
+	}
+}
+
+func TestWatcher_Resync(t *testing.T) {
+	fake := newFakeConnect()
+	fake.add("a", map[string]interface{}{"tasks.max": 1})
+
+	watcher := NewWatcher(fake, time.Minute)
+	watcher.ResyncPeriod = time.Nanosecond
+	assert.NoError(t, watcher.Poll())
+	drain(watcher)
+
+	assert.NoError(t, watcher.Poll())
+	events := drain(watcher)
+	assert.Len(t, events, 1)
+	assert.Equal(t, ConnectorResync, events[0].Type)
+	assert.Equal(t, "RUNNING", events[0].NewState)
+}
+
+func TestWatcher_StartStop(t *testing.T) {
+	fake := newFakeConnect()
+	fake.add("a", map[string]interface{}{"tasks.max": 1})
+
+	watcher := NewWatcher(fake, time.Millisecond)
+	events := watcher.Start()
+	event := <-events
+	assert.Equal(t, ConnectorAdded, event.Type)
+
+	watcher.Stop()
+	for range events {
+	}
+	// a poll after Stop does nothing
+	fake.add("b", map[string]interface{}{"tasks.max": 1})
+	assert.NoError(t, watcher.Poll())
+
+	// stopping a watcher that was never started does not block
+	watcher = NewWatcher(fake, time.Millisecond)
+	watcher.Stop()
+	assert.NoError(t, watcher.Poll())
+	_, open := <-watcher.Events()
+	assert.False(t, open)
+}